@@ -3,48 +3,84 @@ package main
 import (
 	"context"
 	"fmt"
-	"io"
 	"os"
-	"strings"
+	"path/filepath"
 
 	"sync" // Import sync package for Mutex
 
+	"Genpilot/internal/automation"
 	"Genpilot/internal/config"
+	"Genpilot/internal/fileinfo"
+	"Genpilot/internal/history"
+	"Genpilot/internal/logging"
 	"Genpilot/internal/sftp"
 	sshclient "Genpilot/internal/ssh"
+	"Genpilot/internal/terminal"
 	"Genpilot/internal/transfer" // Import transfer package
+	"Genpilot/internal/zmodem"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 	"github.com/zalando/go-keyring"
-	"golang.org/x/crypto/ssh"
 )
 
 // SessionState holds all resources for an active connection
 type SessionState struct {
 	ID            string
 	Name          string
+	Host          string
+	User          string
+	Config        config.Session // saved settings, zero if connected ad hoc
 	SSHClient     *sshclient.Client
 	SFTPClient    *sftp.Client
-	SSHSession    *ssh.Session
-	SSHStdin      io.WriteCloser
 	TransferQueue *transfer.TransferQueue
 	Tunnels       map[string]*sshclient.Tunnel
+	Shells        map[string]*ShellChannel
+	nextShell     int
+	ctx           context.Context // cancelled when the session ends
+	cancel        context.CancelFunc
 }
 
 // App struct
 type App struct {
 	ctx          context.Context
 	sessions     map[string]*SessionState
+	shells       map[string]*ShellChannel // all shell channels by channel ID
 	sessionsLock sync.RWMutex
 	sessionMgr   *config.SessionManager
+	triggerMgr   *config.TriggerManager
+	snippetMgr   *config.SnippetManager
+	settingsMgr  *config.SettingsManager
+	history      *history.Store
+	broadcast    broadcastGroup
+	runs         map[string]*parallelRun
+	runsLock     sync.Mutex
+	commands     map[string]*commandRun
+	commandsLock sync.Mutex
+	players      map[string]*logging.Player
+	playersLock  sync.Mutex
+	edits        map[string]*editSession // files open in a local editor, by session
+	editsLock    sync.Mutex
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
 	sm, _ := config.NewSessionManager()
+	tm, _ := config.NewTriggerManager()
+	snm, _ := config.NewSnippetManager()
+	stm, _ := config.NewSettingsManager()
+	hs, _ := history.NewStore()
 	return &App{
-		sessionMgr: sm,
-		sessions:   make(map[string]*SessionState),
+		sessionMgr:  sm,
+		triggerMgr:  tm,
+		snippetMgr:  snm,
+		settingsMgr: stm,
+		history:     hs,
+		sessions:    make(map[string]*SessionState),
+		shells:      make(map[string]*ShellChannel),
+		runs:        make(map[string]*parallelRun),
+		commands:    make(map[string]*commandRun),
+		players:     make(map[string]*logging.Player),
+		edits:       make(map[string]*editSession),
 	}
 }
 
@@ -68,135 +104,113 @@ func (a *App) Connect(id, name, host string, port int, user string, pass string)
 		return "", fmt.Errorf("connection failed: %w", err)
 	}
 
-	// Create Session State
-	state := &SessionState{
-		ID:            id,
-		Name:          name,
-		SSHClient:     client,
-		TransferQueue: transfer.NewTransferQueue(nil, 2),
-		Tunnels:       make(map[string]*sshclient.Tunnel),
+	// Saved settings for this connection, if any
+	var cfg config.Session
+	if a.sessionMgr != nil {
+		cfg, _ = a.sessionMgr.FindSession(name)
 	}
-	a.sessionsLock.Lock()
-	a.sessions[id] = state
-	a.sessionsLock.Unlock()
-
-	// Set transfer queue callback
-	state.TransferQueue.SetOnChange(func() {
-		if a.ctx != nil {
-			runtime.EventsEmit(a.ctx, "transfer-update-"+id, state.TransferQueue.GetItems())
-		}
-	})
+	cfg.Protocol = config.ProtocolSSH
 
-	// Writer that emits events to frontend with ID
-	writer := &eventWriter{
-		ctx: a.ctx,
-		id:  id,
-		onLogout: func() {
-			go func() {
-				runtime.LogInfo(a.ctx, "Logout detected for "+id)
-				a.DisconnectSession(id)
-			}()
-		},
-	}
-
-	// Prepare Shell
-	session, err := client.PrepareShell(80, 24)
-	if err != nil {
+	if err := a.startShellSession(id, name, host, user, cfg, client); err != nil {
 		return "", err
 	}
-	state.SSHSession = session
+	return "Connected", nil
+}
 
-	// Setup Pipes
-	stdoutPipe, err := session.StdoutPipe()
-	if err != nil {
-		return "", err
-	}
-	stderrPipe, err := session.StderrPipe()
-	if err != nil {
-		return "", err
-	}
-	stdinPipe, err := session.StdinPipe()
-	if err != nil {
-		return "", err
-	}
-	state.SSHStdin = stdinPipe
+// runLoginScript executes a session's login script against its shell and
+// reports a failure both as an event and inline in the terminal
+func (a *App) runLoginScript(ctx context.Context, id string, steps []config.LoginStep, exp *automation.Expecter, shell *ShellChannel) {
+	defer shell.Expect.remove(exp)
+	defer exp.Close()
 
-	// Start Shell
-	if err := session.Shell(); err != nil {
-		return "", fmt.Errorf("shell failed: %w", err)
+	err := automation.Run(ctx, steps, exp, shell.Charset.Writer(shell.Stdin))
+	if err == nil || ctx.Err() != nil || a.ctx == nil {
+		return
 	}
 
-	// Start Copyroutines
-	go func() {
-		_, _ = io.Copy(writer, stdoutPipe)
-		if state.SSHStdin != nil {
-			state.SSHStdin.Close()
-		}
-		if session != nil {
-			_ = session.Close()
-		}
-	}()
-
-	go func() {
-		_, _ = io.Copy(writer, stderrPipe)
-	}()
-
-	// Monitor session closure
-	go func() {
-		_ = session.Wait()
-		if a.ctx != nil {
-			runtime.EventsEmit(a.ctx, "disconnected-"+id, "Disconnected")
-		}
-		a.DisconnectSession(id)
-	}()
-
-	// Initialize SFTP
-	sftpClient, err := sftp.NewClient(client.GetClient())
-	if err == nil {
-		state.SFTPClient = sftpClient
-		state.TransferQueue.SetClient(sftpClient.GetSFTPClient())
-	}
+	runtime.LogError(a.ctx, "Login script failed for "+id+": "+err.Error())
+	runtime.EventsEmit(a.ctx, "login-script-failed-"+id, err.Error())
+	shell.Output.Write([]byte("\r\n[Login script failed: " + err.Error() + "]\r\n"))
+}
 
-	return "Connected", nil
+// eventWriter implements io.Writer and fans shell output out to the
+// frontend and the channel's consumers
+type eventWriter struct {
+	mu         sync.Mutex // stdout and stderr share the writer
+	expect     *expectTaps
+	logger     *logging.Logger
+	recorder   *logging.Recorder
+	scrollback *terminal.Scrollback
+	triggers   *automation.Triggers
+	osc        terminal.OSCScanner
+	history    *history.Tracker
+	output     *terminal.Batcher
+	charset    *terminal.Charset
+	onOSC      func(terminal.OSC)
+
+	// A ZMODEM transfer takes the stream over until it ends
+	zdetect     zmodem.Detector
+	zmodem      *zmodem.Session
+	startZmodem func(zmodem.Direction) *zmodem.Session
 }
 
-func (a *App) ResizeTerminal(id string, rows, cols int) {
-	a.sessionsLock.RLock()
-	s, ok := a.sessions[id]
-	a.sessionsLock.RUnlock()
+func (w *eventWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 
-	if ok && s.SSHClient != nil && s.SSHSession != nil {
-		s.SSHClient.ResizeTerminal(s.SSHSession, rows, cols)
+	n = len(p)
+	if w.zmodem != nil {
+		if w.zmodem.Feed(p) {
+			return n, nil
+		}
+		// The transfer is over; what followed it belongs to the terminal
+		p = append(w.zmodem.Leftover(), p...)
+		w.zmodem = nil
+	}
+	if w.startZmodem != nil {
+		if start, prefix, dir, found := w.zdetect.Scan(p); found {
+			w.consume(p[:start])
+			w.zmodem = w.startZmodem(dir)
+			w.zmodem.Feed(append(prefix, p[start:]...))
+			return n, nil
+		}
 	}
+	if err := w.consume(p); err != nil {
+		return 0, err
+	}
+	return n, nil
 }
 
-func (a *App) WriteToTerminal(id string, data string) {
-	a.sessionsLock.RLock()
-	s, ok := a.sessions[id]
-	a.sessionsLock.RUnlock()
+// handBack returns the stream to the terminal once a transfer has ended,
+// passing on output that arrived after it
+func (w *eventWriter) handBack(sess *zmodem.Session) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 
-	if ok && s.SSHStdin != nil {
-		s.SSHStdin.Write([]byte(data))
+	if w.zmodem == sess {
+		w.zmodem = nil
+		w.consume(sess.Leftover())
 	}
 }
 
-// eventWriter implements io.Writer and emits events
-type eventWriter struct {
-	ctx      context.Context
-	id       string
-	onLogout func()
-}
-
-func (w *eventWriter) Write(p []byte) (n int, err error) {
-	s := string(p)
-	lower := strings.ToLower(s)
-	if strings.Contains(lower, "logout") || strings.Contains(lower, "connection closed") {
-		if w.onLogout != nil {
-			w.onLogout()
-		}
+// consume hands terminal output to everything that watches it
+func (w *eventWriter) consume(p []byte) error {
+	// Everything downstream works in UTF-8
+	p = w.charset.Decode(p)
+	if len(p) == 0 {
+		return nil
 	}
-	runtime.EventsEmit(w.ctx, "terminal-data-"+w.id, s)
-	return len(p), nil
+	w.expect.Write(p)
+	w.logger.WriteOutput(p)
+	w.recorder.WriteOutput(p)
+	w.scrollback.Write(p)
+	w.triggers.Write(p)
+	for _, o := range w.osc.Scan(p) {
+		w.onOSC(o)
+	}
+	w.history.Output(p)
+	_, err := w.output.Write(p)
+	return err
 }
 
 // SFTP Methods
@@ -205,10 +219,61 @@ type FileItem struct {
 	Size  string `json:"size"`
 	Mode  string `json:"mode"`
 	Time  string `json:"time"`
-	IsDir bool   `json:"is_dir"`
-}
-
-func (a *App) ListFiles(id, path string) ([]FileItem, error) {
+	IsDir bool   `json:"is_dir"` // true for symlinks to directories too
+
+	Bytes  int64  `json:"bytes"`
+	MTime  int64  `json:"mtime"` // Unix seconds
+	Perm   string `json:"perm"`  // octal, e.g. 0755
+	UID    int    `json:"uid"`   // -1 if unknown, as is GID
+	GID    int    `json:"gid"`
+	Owner  string `json:"owner,omitempty"`
+	Group  string `json:"group,omitempty"`
+	Hidden bool   `json:"hidden"`
+
+	IsLink     bool   `json:"is_link"`
+	LinkTarget string `json:"link_target,omitempty"`
+	LinkType   string `json:"link_type,omitempty"` // dir, file or other; empty if broken
+	Broken     bool   `json:"broken,omitempty"`    // the link points to nothing
+}
+
+// newFileItem describes a directory entry. For a symlink, target is what
+// it points to, nil if that doesn't exist.
+func newFileItem(info os.FileInfo, linkTarget string, target os.FileInfo) FileItem {
+	item := FileItem{
+		Name:   info.Name(),
+		Size:   fmt.Sprintf("%d", info.Size()),
+		Mode:   info.Mode().String(),
+		Time:   info.ModTime().Format("2006-01-02 15:04"),
+		IsDir:  info.IsDir(),
+		Bytes:  info.Size(),
+		MTime:  info.ModTime().Unix(),
+		Perm:   fmt.Sprintf("%04o", fileinfo.Perm(info.Mode())),
+		UID:    -1,
+		GID:    -1,
+		Hidden: fileinfo.Hidden(info),
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return item
+	}
+
+	item.IsLink = true
+	item.LinkTarget = linkTarget
+	switch {
+	case target == nil:
+		item.Broken = true
+	case target.IsDir():
+		item.IsDir = true
+		item.LinkType = "dir"
+	case target.Mode().IsRegular():
+		item.LinkType = "file"
+	default:
+		item.LinkType = "other"
+	}
+	return item
+}
+
+// ListFiles lists a remote directory, sorted and filtered as opts asks
+func (a *App) ListFiles(id, path string, opts ListOptions) ([]FileItem, error) {
 	a.sessionsLock.RLock()
 	s, ok := a.sessions[id]
 	a.sessionsLock.RUnlock()
@@ -216,26 +281,30 @@ func (a *App) ListFiles(id, path string) ([]FileItem, error) {
 	if !ok || s.SFTPClient == nil {
 		return nil, fmt.Errorf("SFTP not connected for session %s", id)
 	}
+	if path == "" {
+		// Follow the terminal when its shell reports where it is
+		path = a.sessionCwd(id)
+	}
 	if path == "" {
 		path = "."
 	}
 
-	entries, err := s.SFTPClient.ListDirectory(path)
+	entries, err := s.SFTPClient.ListEntries(path)
 	if err != nil {
 		return nil, err
 	}
 
+	users, groups := s.SFTPClient.IDNames()
 	var files []FileItem
 	for _, e := range entries {
-		files = append(files, FileItem{
-			Name:  e.Name(),
-			Size:  fmt.Sprintf("%d", e.Size()),
-			Mode:  e.Mode().String(),
-			Time:  e.ModTime().Format("2006-01-02 15:04"),
-			IsDir: e.IsDir(),
-		})
+		item := newFileItem(e.Info, e.LinkTarget, e.Target)
+		if uid, gid, ok := sftp.Owner(e.Info); ok {
+			item.UID, item.GID = int(uid), int(gid)
+			item.Owner, item.Group = users[uid], groups[gid]
+		}
+		files = append(files, item)
 	}
-	return files, nil
+	return listFiles(files, opts)
 }
 
 func (a *App) GoUp(id, path string) string {
@@ -252,17 +321,32 @@ func (a *App) GoUp(id, path string) string {
 // Session Management Methods
 
 func (a *App) SaveSession(name, host, user, pass, group string, port int) error {
-	session := config.Session{
-		Name:     name,
-		Host:     host,
-		Port:     port,
-		Username: user,
-		Password: pass,
-		Group:    group,
-	}
+	// Keep settings that aren't edited through this form
+	session, _ := a.sessionMgr.FindSession(name)
+	session.Name = name
+	session.Host = host
+	session.Port = port
+	session.Username = user
+	session.Password = pass
+	session.Group = group
 	return a.sessionMgr.AddSession(session)
 }
 
+// SaveLoginScript sets the steps run after login for a saved session
+func (a *App) SaveLoginScript(name string, steps []config.LoginStep) error {
+	return a.sessionMgr.SetLoginScript(name, steps)
+}
+
+// SaveSecret stores a named secret in the keyring for use by login scripts
+func (a *App) SaveSecret(name, value string) error {
+	return config.SetSecret(name, value)
+}
+
+// DeleteSecret removes a named secret from the keyring
+func (a *App) DeleteSecret(name string) error {
+	return config.DeleteSecret(name)
+}
+
 func (a *App) GetSessionPassword(name string) string {
 	pass, err := keyring.Get("Genpilot", name)
 	if err != nil {
@@ -279,7 +363,26 @@ func (a *App) DeleteSession(name string) error {
 	return a.sessionMgr.DeleteSession(name)
 }
 
+// DisconnectSession closes a connection along with its shells and tunnels
 func (a *App) DisconnectSession(id string) {
+	a.endSession(id, DisconnectInfo{Reason: EndUser, Code: -1, Message: "Disconnected"})
+}
+
+// connectionClosed tears a session down once its transport has gone away
+func (a *App) connectionClosed(state *SessionState, err error) {
+	a.sessionsLock.RLock()
+	current := a.sessions[state.ID] == state
+	a.sessionsLock.RUnlock()
+	if !current {
+		// Closed on purpose
+		return
+	}
+
+	a.endSession(state.ID, connectionLost(err))
+}
+
+// endSession removes a session and reports why on each of its terminals
+func (a *App) endSession(id string, info DisconnectInfo) {
 	a.sessionsLock.Lock()
 	s, ok := a.sessions[id]
 	if !ok {
@@ -288,19 +391,34 @@ func (a *App) DisconnectSession(id string) {
 	}
 	// We found it, now we also remove it from the map immediately so no one else picks it up
 	delete(a.sessions, id)
+	shells := make([]*ShellChannel, 0, len(s.Shells))
+	for chID, ch := range s.Shells {
+		delete(a.shells, chID)
+		shells = append(shells, ch)
+	}
+	s.Shells = make(map[string]*ShellChannel)
 	a.sessionsLock.Unlock()
 
-	runtime.LogInfo(a.ctx, "Disconnecting session "+id)
+	runtime.LogInfo(a.ctx, "Disconnecting session "+id+": "+info.Message)
+	info.Session = true
+
+	if s.cancel != nil {
+		s.cancel()
+	}
 
 	for _, tunnel := range s.Tunnels {
 		tunnel.Stop()
 	}
+	a.closeEdits(id)
 
-	if s.SSHStdin != nil {
-		s.SSHStdin.Close()
-	}
-	if s.SSHSession != nil {
-		s.SSHSession.Close()
+	for _, ch := range shells {
+		ch.close()
+		ch.Logger.Stop()
+		ch.Recorder.Stop()
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "shell-closed-"+ch.ID, info)
+		}
+		a.RemoveBroadcastMember(ch.ID)
 	}
 	if s.SSHClient != nil {
 		s.SSHClient.Close()
@@ -309,7 +427,7 @@ func (a *App) DisconnectSession(id string) {
 	// removed from sessions map above
 
 	if a.ctx != nil {
-		runtime.EventsEmit(a.ctx, "disconnected-"+id, "Disconnected")
+		runtime.EventsEmit(a.ctx, "disconnected-"+id, info)
 	}
 }
 
@@ -379,7 +497,8 @@ func (a *App) DeleteRemoteFile(id, path string) error {
 	if !ok || s.SFTPClient == nil {
 		return fmt.Errorf("not connected for session %s", id)
 	}
-	stat, err := s.SFTPClient.Stat(path)
+	// Lstat, so deleting a link to a directory leaves the directory alone
+	stat, err := s.SFTPClient.Lstat(path)
 	if err != nil {
 		return err
 	}
@@ -440,7 +559,8 @@ func (a *App) SelectSavePath(filename string) (string, error) {
 
 // Local Filesystem Methods
 
-func (a *App) ListLocalFiles(path string) ([]FileItem, error) {
+// ListLocalFiles lists a local directory, sorted and filtered as opts asks
+func (a *App) ListLocalFiles(path string, opts ListOptions) ([]FileItem, error) {
 	if path == "" {
 		var err error
 		path, err = os.Getwd()
@@ -460,15 +580,21 @@ func (a *App) ListLocalFiles(path string) ([]FileItem, error) {
 		if err != nil {
 			continue
 		}
-		files = append(files, FileItem{
-			Name:  e.Name(),
-			Size:  fmt.Sprintf("%d", info.Size()),
-			Mode:  info.Mode().String(),
-			Time:  info.ModTime().Format("2006-01-02 15:04"),
-			IsDir: e.IsDir(),
-		})
+		var linkTarget string
+		var target os.FileInfo
+		if info.Mode()&os.ModeSymlink != 0 {
+			p := filepath.Join(path, e.Name())
+			linkTarget, _ = os.Readlink(p)
+			target, _ = os.Stat(p)
+		}
+		item := newFileItem(info, linkTarget, target)
+		if uid, gid, ok := fileinfo.Owner(info); ok {
+			item.UID, item.GID = int(uid), int(gid)
+			item.Owner, item.Group = fileinfo.UserName(uid), fileinfo.GroupName(gid)
+		}
+		files = append(files, item)
 	}
-	return files, nil
+	return listFiles(files, opts)
 }
 
 func (a *App) GetLocalWD() (string, error) {
@@ -514,23 +640,53 @@ func (a *App) StartLocalForward(id string, localPort int, remoteHost string, rem
 // StopLocalForward stops an active SSH local port forwarding tunnel
 func (a *App) StopLocalForward(id string, tunnelId string) error {
 	a.sessionsLock.Lock()
-	defer a.sessionsLock.Unlock()
-
 	s, ok := a.sessions[id]
 	if !ok {
+		a.sessionsLock.Unlock()
 		return fmt.Errorf("session %s not found", id)
 	}
 
 	tunnel, exists := s.Tunnels[tunnelId]
 	if !exists {
+		a.sessionsLock.Unlock()
 		return fmt.Errorf("tunnel %s not found", tunnelId)
 	}
 
 	tunnel.Stop()
 	delete(s.Tunnels, tunnelId)
+	a.sessionsLock.Unlock()
+
+	// The connection may only have stayed open for this tunnel
+	a.disconnectIfIdle(id)
 	return nil
 }
 
+// disconnectIfIdle closes a session once nothing uses its connection any
+// more: no shells, no tunnels, no transfers and no remote edits
+func (a *App) disconnectIfIdle(id string) {
+	a.sessionsLock.RLock()
+	s, ok := a.sessions[id]
+	idle := ok && len(s.Shells) == 0 && len(s.Tunnels) == 0
+	a.sessionsLock.RUnlock()
+
+	if idle && !a.filesInUse(s) {
+		a.DisconnectSession(id)
+	}
+}
+
+// filesInUse reports whether a session's connection is still needed for
+// its files: transfers queued or running, or remote files being edited
+func (a *App) filesInUse(s *SessionState) bool {
+	if pending, active, _, _ := s.TransferQueue.GetStats(); pending+active > 0 {
+		return true
+	}
+
+	a.editsLock.Lock()
+	defer a.editsLock.Unlock()
+	es, ok := a.edits[s.ID]
+	return ok && len(es.edits) > 0
+}
+
 // GetActiveTunnels returns a list of active tunnels for the session
 func (a *App) GetActiveTunnels(id string) []TunnelInfo {
 	a.sessionsLock.RLock()
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// broadcastGroup fans terminal input out to several shell channels (MultiExec)
+type broadcastGroup struct {
+	mu      sync.Mutex
+	members map[string]bool // channel ID -> receiving input
+}
+
+// BroadcastMember describes a broadcast group member to the frontend
+type BroadcastMember struct {
+	ID      string `json:"id"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (b *broadcastGroup) list() []BroadcastMember {
+	result := make([]BroadcastMember, 0, len(b.members))
+	for id, enabled := range b.members {
+		result = append(result, BroadcastMember{ID: id, Enabled: enabled})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// emitBroadcastMembers tells the frontend which terminals receive broadcast input
+func (a *App) emitBroadcastMembers(members []BroadcastMember) {
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "broadcast-members", members)
+	}
+}
+
+// SetBroadcastMembers replaces the broadcast group with the given shell channels, all enabled
+func (a *App) SetBroadcastMembers(ids []string) {
+	a.broadcast.mu.Lock()
+	a.broadcast.members = make(map[string]bool, len(ids))
+	for _, id := range ids {
+		a.broadcast.members[id] = true
+	}
+	members := a.broadcast.list()
+	a.broadcast.mu.Unlock()
+
+	a.emitBroadcastMembers(members)
+}
+
+// SetBroadcastMemberEnabled pauses or resumes broadcast input for one member
+func (a *App) SetBroadcastMemberEnabled(id string, enabled bool) {
+	a.broadcast.mu.Lock()
+	if _, ok := a.broadcast.members[id]; !ok {
+		a.broadcast.mu.Unlock()
+		return
+	}
+	a.broadcast.members[id] = enabled
+	members := a.broadcast.list()
+	a.broadcast.mu.Unlock()
+
+	a.emitBroadcastMembers(members)
+}
+
+// RemoveBroadcastMember drops a shell channel from the broadcast group
+func (a *App) RemoveBroadcastMember(id string) {
+	a.broadcast.mu.Lock()
+	if _, ok := a.broadcast.members[id]; !ok {
+		a.broadcast.mu.Unlock()
+		return
+	}
+	delete(a.broadcast.members, id)
+	members := a.broadcast.list()
+	a.broadcast.mu.Unlock()
+
+	a.emitBroadcastMembers(members)
+}
+
+// GetBroadcastMembers returns the current broadcast group
+func (a *App) GetBroadcastMembers() []BroadcastMember {
+	a.broadcast.mu.Lock()
+	defer a.broadcast.mu.Unlock()
+	return a.broadcast.list()
+}
+
+// WriteBroadcast sends input to every enabled member of the broadcast group
+func (a *App) WriteBroadcast(data string) {
+	for _, id := range a.broadcastTargets() {
+		a.WriteToTerminal(id, data)
+	}
+}
+
+// broadcastTargets returns the enabled broadcast members
+func (a *App) broadcastTargets() []string {
+	a.broadcast.mu.Lock()
+	defer a.broadcast.mu.Unlock()
+
+	ids := make([]string, 0, len(a.broadcast.members))
+	for id, enabled := range a.broadcast.members {
+		if enabled {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
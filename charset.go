@@ -0,0 +1,51 @@
+package main
+
+import "Genpilot/internal/terminal"
+
+// commonCharsets are offered for hosts that don't use UTF-8; any WHATWG or
+// IANA name is accepted as well
+var commonCharsets = []string{
+	"UTF-8",
+	"ISO-8859-1",
+	"ISO-8859-2",
+	"ISO-8859-5",
+	"ISO-8859-9",
+	"ISO-8859-15",
+	"windows-1250",
+	"windows-1251",
+	"windows-1252",
+	"windows-1254",
+	"KOI8-R",
+	"Shift_JIS",
+	"EUC-JP",
+	"EUC-KR",
+	"GBK",
+	"Big5",
+	"IBM437",
+}
+
+// GetCharsets lists the character sets to offer in the session editor
+func (a *App) GetCharsets() []string {
+	return commonCharsets
+}
+
+// SaveCharset sets the character set of a saved session's host. Output is
+// converted from it to UTF-8 and input back, starting with shells opened
+// afterwards. An empty name or UTF-8 turns conversion off.
+func (a *App) SaveCharset(name, charset string) error {
+	if _, err := terminal.LookupCharset(charset); err != nil {
+		return err
+	}
+	if err := a.sessionMgr.SetCharset(name, charset); err != nil {
+		return err
+	}
+
+	a.sessionsLock.Lock()
+	for _, s := range a.sessions {
+		if s.Name == name {
+			s.Config.Charset = charset
+		}
+	}
+	a.sessionsLock.Unlock()
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	sshclient "Genpilot/internal/ssh"
+	"Genpilot/internal/terminal"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/crypto/ssh"
+)
+
+// commandRun is a one-off command running on its own exec channel
+type commandRun struct {
+	exec   *sshclient.Exec
+	cancel context.CancelFunc
+}
+
+// CommandExit is emitted when a one-off command finishes
+type CommandExit struct {
+	Code   int    `json:"code"`
+	Signal string `json:"signal,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// StartCommand runs a command on session id without touching its shell.
+// The caller picks execID, as with Connect, so it can subscribe before
+// anything is sent. Output is streamed as exec-stdout-<execID> and
+// exec-stderr-<execID> events and the result as an exec-exit-<execID> event.
+func (a *App) StartCommand(id, execID, command string, pty bool, rows, cols int) error {
+	if execID == "" {
+		return fmt.Errorf("command ID required")
+	}
+
+	a.sessionsLock.RLock()
+	s, ok := a.sessions[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok || s.SSHClient == nil {
+		return fmt.Errorf("session %s not connected", id)
+	}
+
+	a.commandsLock.Lock()
+	if _, exists := a.commands[execID]; exists {
+		a.commandsLock.Unlock()
+		return fmt.Errorf("command %s already running", execID)
+	}
+	// Held until started so the ID can't be taken twice
+	run := &commandRun{}
+	a.commands[execID] = run
+	a.commandsLock.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e, err := s.SSHClient.Start(ctx, sshclient.ExecOptions{
+		Command: command,
+		PTY:     pty,
+		Rows:    rows,
+		Cols:    cols,
+	})
+	if err != nil {
+		cancel()
+		a.commandsLock.Lock()
+		delete(a.commands, execID)
+		a.commandsLock.Unlock()
+		return err
+	}
+
+	a.commandsLock.Lock()
+	run.exec, run.cancel = e, cancel
+	a.commandsLock.Unlock()
+
+	var wg sync.WaitGroup
+	stream := func(r io.Reader, event string) {
+		defer wg.Done()
+		buf := make([]byte, 32*1024)
+		var tail []byte
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				// Events carry strings, so hold back split characters
+				var complete []byte
+				complete, tail = terminal.SplitIncompleteUTF8(append(tail, buf[:n]...))
+				if len(complete) > 0 && a.ctx != nil {
+					runtime.EventsEmit(a.ctx, event, string(complete))
+				}
+				tail = append([]byte(nil), tail...)
+			}
+			if err != nil {
+				if len(tail) > 0 && a.ctx != nil {
+					runtime.EventsEmit(a.ctx, event, string(tail))
+				}
+				return
+			}
+		}
+	}
+	wg.Add(2)
+	go stream(e.Stdout, "exec-stdout-"+execID)
+	go stream(e.Stderr, "exec-stderr-"+execID)
+
+	go func() {
+		wg.Wait()
+		status, err := e.Wait()
+		e.Close()
+		cancel()
+
+		a.commandsLock.Lock()
+		delete(a.commands, execID)
+		a.commandsLock.Unlock()
+
+		exit := CommandExit{Code: status.Code, Signal: status.Signal}
+		if err != nil {
+			exit.Error = err.Error()
+		}
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "exec-exit-"+execID, exit)
+		}
+	}()
+
+	return nil
+}
+
+func (a *App) command(execID string) (*commandRun, error) {
+	a.commandsLock.Lock()
+	defer a.commandsLock.Unlock()
+
+	c, ok := a.commands[execID]
+	if !ok || c.exec == nil {
+		return nil, fmt.Errorf("command %s not running", execID)
+	}
+	return c, nil
+}
+
+// WriteCommandInput sends data to the stdin of a running command
+func (a *App) WriteCommandInput(execID string, data string) error {
+	c, err := a.command(execID)
+	if err != nil {
+		return err
+	}
+	_, err = c.exec.Stdin.Write([]byte(data))
+	return err
+}
+
+// CloseCommandInput sends EOF to a running command
+func (a *App) CloseCommandInput(execID string) error {
+	c, err := a.command(execID)
+	if err != nil {
+		return err
+	}
+	return c.exec.Stdin.Close()
+}
+
+// SignalCommand delivers a signal such as "INT" or "KILL" to a running command
+func (a *App) SignalCommand(execID string, signal string) error {
+	c, err := a.command(execID)
+	if err != nil {
+		return err
+	}
+	return c.exec.Signal(ssh.Signal(signal))
+}
+
+// ResizeCommand changes the PTY size of a command started with a PTY
+func (a *App) ResizeCommand(execID string, rows, cols int) error {
+	c, err := a.command(execID)
+	if err != nil {
+		return err
+	}
+	return c.exec.Resize(rows, cols)
+}
+
+// CancelCommand terminates a running command
+func (a *App) CancelCommand(execID string) {
+	if c, err := a.command(execID); err == nil {
+		c.cancel()
+	}
+}
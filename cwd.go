@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+
+	"Genpilot/internal/terminal"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// cwdHook makes bash and zsh report the working directory with OSC 7 at
+// every prompt. The leading space keeps it out of history where
+// HISTCONTROL ignores commands starting with a space. A shell variable
+// marks shells that have it, so sending it again, as into a reattached
+// tmux or screen session, changes nothing.
+const cwdHook = ` if [ -z "$_genpilot_hook" ]; then _genpilot_hook=1; if [ -n "$ZSH_VERSION" ]; then _genpilot_cwd() { printf '\033]7;file://%s%s\007' "$HOST" "$PWD"; }; precmd_functions+=(_genpilot_cwd); elif [ -n "$BASH_VERSION" ]; then PROMPT_COMMAND='printf "\033]7;file://%s%s\007" "$HOSTNAME" "$PWD"'"${PROMPT_COMMAND:+;$PROMPT_COMMAND}"; fi; fi` + "\r"
+
+// injectCwdHook installs the prompt hook if the session asks for it. Only
+// SSH and local sessions are known to end up at a POSIX shell; telnet and
+// raw sessions are usually devices that would take it as a command.
+func (a *App) injectCwdHook(state *SessionState, ch *ShellChannel) {
+	if !state.Config.TrackCwd || !state.posixShell() {
+		return
+	}
+	if _, err := ch.Stdin.Write([]byte(cwdHook)); err != nil {
+		runtime.LogError(a.ctx, "Working directory hook failed for "+ch.ID+": "+err.Error())
+	}
+}
+
+// handleOSC acts on OSC sequences found in a shell's output
+func (a *App) handleOSC(ch *ShellChannel, o terminal.OSC) {
+	dir, ok := o.WorkingDir()
+	if !ok {
+		return
+	}
+
+	if ch.setWorkingDir(dir) && a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "cwd-"+ch.ID, dir)
+	}
+}
+
+// GetWorkingDir returns the last directory a terminal reported, or "" if
+// its shell hasn't reported one
+func (a *App) GetWorkingDir(id string) (string, error) {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok {
+		return "", fmt.Errorf("terminal %s not connected", id)
+	}
+	return ch.workingDir(), nil
+}
+
+// sessionCwd returns the directory of a session's first terminal, falling
+// back to any of its terminals that reported one
+func (a *App) sessionCwd(id string) string {
+	a.sessionsLock.RLock()
+	defer a.sessionsLock.RUnlock()
+
+	s, ok := a.sessions[id]
+	if !ok {
+		return ""
+	}
+	if ch, ok := s.Shells[id]; ok {
+		if dir := ch.workingDir(); dir != "" {
+			return dir
+		}
+	}
+	for _, ch := range s.Shells {
+		if dir := ch.workingDir(); dir != "" {
+			return dir
+		}
+	}
+	return ""
+}
+
+// SaveCwdTracking turns the working directory prompt hook on or off for a
+// saved session. It takes effect for shells opened afterwards.
+func (a *App) SaveCwdTracking(name string, enabled bool) error {
+	if err := a.sessionMgr.SetTrackCwd(name, enabled); err != nil {
+		return err
+	}
+
+	a.sessionsLock.Lock()
+	for _, s := range a.sessions {
+		if s.Name == name {
+			s.Config.TrackCwd = enabled
+		}
+	}
+	a.sessionsLock.Unlock()
+	return nil
+}
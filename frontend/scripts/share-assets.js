@@ -0,0 +1,13 @@
+// Copies xterm.js into the Go share package, which embeds it in the page
+// served to people watching a shared terminal
+import { copyFileSync, mkdirSync } from "fs";
+import { dirname, join } from "path";
+import { fileURLToPath } from "url";
+
+const root = join(dirname(fileURLToPath(import.meta.url)), "..");
+const xterm = join(root, "node_modules", "xterm");
+const out = join(root, "..", "internal", "share", "assets");
+
+mkdirSync(out, { recursive: true });
+copyFileSync(join(xterm, "lib", "xterm.js"), join(out, "xterm.js"));
+copyFileSync(join(xterm, "css", "xterm.css"), join(out, "xterm.css"));
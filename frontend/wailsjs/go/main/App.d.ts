@@ -1,55 +1,234 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
-import {transfer} from '../models';
+import {sftp} from '../models';
+import {multiexec} from '../models';
 import {config} from '../models';
+import {transfer} from '../models';
+import {mux} from '../models';
+import {history} from '../models';
+import {terminal} from '../models';
+
+export function AckTerminalData(arg1:string):Promise<void>;
+
+export function AttachMultiplexer(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function CancelCommand(arg1:string):Promise<void>;
+
+export function CancelRun(arg1:string):Promise<void>;
 
 export function CancelTransfer(arg1:string,arg2:number):Promise<void>;
 
+export function CancelZmodem(arg1:string):Promise<void>;
+
+export function ChangeMode(arg1:string,arg2:Array<string>,arg3:main.ChmodOptions):Promise<Array<sftp.Change>>;
+
+export function ChangeOwner(arg1:string,arg2:Array<string>,arg3:main.ChownOptions):Promise<Array<sftp.Change>>;
+
 export function ClearCompletedTransfers(arg1:string):Promise<void>;
 
+export function ClearHistory(arg1:string):Promise<void>;
+
+export function ClearRun(arg1:string):Promise<void>;
+
+export function CloseCommandInput(arg1:string):Promise<void>;
+
+export function CloseRecording(arg1:string):Promise<void>;
+
+export function CloseShell(arg1:string):Promise<void>;
+
 export function Connect(arg1:string,arg2:string,arg3:string,arg4:number,arg5:string,arg6:string):Promise<string>;
 
+export function ConnectLocal(arg1:string,arg2:string):Promise<string>;
+
+export function ConnectRaw(arg1:string,arg2:string,arg3:string,arg4:number):Promise<string>;
+
+export function ConnectTelnet(arg1:string,arg2:string,arg3:string,arg4:number):Promise<string>;
+
+export function CreateHardLink(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function CreateSymlink(arg1:string,arg2:string,arg3:string):Promise<void>;
+
 export function DeleteRemoteFile(arg1:string,arg2:string):Promise<void>;
 
+export function DeleteSecret(arg1:string):Promise<void>;
+
 export function DeleteSession(arg1:string):Promise<void>;
 
+export function DeleteSnippet(arg1:string):Promise<void>;
+
+export function DeleteTrigger(arg1:string):Promise<void>;
+
 export function DisconnectAll():Promise<void>;
 
 export function DisconnectSession(arg1:string):Promise<void>;
 
 export function DownloadFile(arg1:string,arg2:string,arg3:string):Promise<void>;
 
+export function EditRemoteFile(arg1:string,arg2:string):Promise<main.RemoteEdit>;
+
+export function ExportRecording(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function ExportRunResults(arg1:string,arg2:string):Promise<void>;
+
 export function GetActiveTunnels(arg1:string):Promise<Array<main.TunnelInfo>>;
 
+export function GetBroadcastMembers():Promise<Array<main.BroadcastMember>>;
+
+export function GetCharsets():Promise<Array<string>>;
+
 export function GetLocalWD():Promise<string>;
 
+export function GetRecordingPath(arg1:string):Promise<string>;
+
+export function GetRemoteEdits(arg1:string):Promise<Array<main.RemoteEdit>>;
+
+export function GetRunReport(arg1:string):Promise<multiexec.Report>;
+
+export function GetScrollback(arg1:string):Promise<string>;
+
+export function GetSessionLogPath(arg1:string):Promise<string>;
+
 export function GetSessionPassword(arg1:string):Promise<string>;
 
+export function GetSessionSnippets(arg1:string):Promise<Array<config.Snippet>>;
+
+export function GetSettings():Promise<config.Settings>;
+
+export function GetShare(arg1:string):Promise<main.ShareInfo>;
+
+export function GetSnippetVariables(arg1:string):Promise<Array<string>>;
+
+export function GetSnippets():Promise<Array<config.Snippet>>;
+
 export function GetTransfers(arg1:string):Promise<Array<transfer.TransferItem>>;
 
+export function GetTriggers():Promise<Array<config.Trigger>>;
+
+export function GetWorkingDir(arg1:string):Promise<string>;
+
 export function GoUp(arg1:string,arg2:string):Promise<string>;
 
-export function ListFiles(arg1:string,arg2:string):Promise<Array<main.FileItem>>;
+export function KickShareViewer(arg1:string,arg2:number):Promise<void>;
+
+export function ListFiles(arg1:string,arg2:string,arg3:main.ListOptions):Promise<Array<main.FileItem>>;
 
-export function ListLocalFiles(arg1:string):Promise<Array<main.FileItem>>;
+export function ListLocalFiles(arg1:string,arg2:main.ListOptions):Promise<Array<main.FileItem>>;
+
+export function ListMultiplexerSessions(arg1:string):Promise<Array<mux.Session>>;
+
+export function ListShells(arg1:string):Promise<Array<main.ShellInfo>>;
 
 export function LoadSessions():Promise<Array<config.Session>>;
 
+export function OpenRecording(arg1:string):Promise<main.PlaybackInfo>;
+
+export function OpenShell(arg1:string):Promise<string>;
+
+export function OverwriteRemoteEdit(arg1:string,arg2:number):Promise<void>;
+
+export function PlaybackPause(arg1:string):Promise<void>;
+
+export function PlaybackPlay(arg1:string):Promise<void>;
+
+export function PlaybackSeek(arg1:string,arg2:number):Promise<void>;
+
+export function PlaybackSetSpeed(arg1:string,arg2:number):Promise<void>;
+
+export function RealPath(arg1:string,arg2:string):Promise<string>;
+
+export function RecallHistory(arg1:string,arg2:string):Promise<void>;
+
+export function ReloadRemoteEdit(arg1:string,arg2:number):Promise<void>;
+
+export function RemoveBroadcastMember(arg1:string):Promise<void>;
+
 export function RenameFile(arg1:string,arg2:string,arg3:string):Promise<void>;
 
+export function ResizeCommand(arg1:string,arg2:number,arg3:number):Promise<void>;
+
 export function ResizeTerminal(arg1:string,arg2:number,arg3:number):Promise<void>;
 
+export function RunParallel(arg1:string,arg2:Array<string>,arg3:string,arg4:string,arg5:number,arg6:number):Promise<void>;
+
+export function RunSnippet(arg1:string,arg2:string,arg3:Record<string, string>):Promise<void>;
+
+export function RunSnippetBroadcast(arg1:string,arg2:Record<string, string>):Promise<void>;
+
+export function SaveCharset(arg1:string,arg2:string):Promise<void>;
+
+export function SaveCwdTracking(arg1:string,arg2:boolean):Promise<void>;
+
+export function SaveEditor(arg1:string):Promise<void>;
+
+export function SaveHistoryOptOut(arg1:string,arg2:boolean):Promise<void>;
+
+export function SaveLocalSession(arg1:string,arg2:string,arg3:string,arg4:string,arg5:Array<string>):Promise<void>;
+
+export function SaveLogSettings(arg1:string,arg2:config.SessionLog):Promise<void>;
+
+export function SaveLoginScript(arg1:string,arg2:Array<config.LoginStep>):Promise<void>;
+
+export function SaveMultiplexer(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<void>;
+
+export function SaveProtocol(arg1:string,arg2:string):Promise<void>;
+
+export function SaveSecret(arg1:string,arg2:string):Promise<void>;
+
 export function SaveSession(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:number):Promise<void>;
 
+export function SaveSessionTriggers(arg1:string,arg2:Array<config.Trigger>):Promise<void>;
+
+export function SaveSnippet(arg1:config.Snippet):Promise<config.Snippet>;
+
+export function SaveTrigger(arg1:config.Trigger):Promise<config.Trigger>;
+
+export function SearchHistory(arg1:string,arg2:string,arg3:number):Promise<Array<history.Entry>>;
+
+export function SearchHostHistory(arg1:string,arg2:string,arg3:number):Promise<Array<history.Entry>>;
+
+export function SearchScrollback(arg1:string,arg2:string,arg3:boolean,arg4:boolean):Promise<Array<terminal.Match>>;
+
+export function SelectRecordingFile():Promise<string>;
+
 export function SelectSavePath(arg1:string):Promise<string>;
 
 export function SelectUploadFile():Promise<string>;
 
+export function SetBroadcastMemberEnabled(arg1:string,arg2:boolean):Promise<void>;
+
+export function SetBroadcastMembers(arg1:Array<string>):Promise<void>;
+
+export function SetShareInput(arg1:string,arg2:boolean):Promise<void>;
+
+export function SetTriggerEnabled(arg1:string,arg2:boolean):Promise<void>;
+
+export function SignalCommand(arg1:string,arg2:string):Promise<void>;
+
+export function StartCommand(arg1:string,arg2:string,arg3:string,arg4:boolean,arg5:number,arg6:number):Promise<void>;
+
 export function StartLocalForward(arg1:string,arg2:number,arg3:string,arg4:number):Promise<void>;
 
+export function StartRecording(arg1:string,arg2:boolean):Promise<string>;
+
+export function StartSessionLog(arg1:string,arg2:config.SessionLog):Promise<string>;
+
+export function StartShare(arg1:string,arg2:string,arg3:boolean):Promise<main.ShareInfo>;
+
 export function StopLocalForward(arg1:string,arg2:string):Promise<void>;
 
+export function StopRecording(arg1:string):Promise<void>;
+
+export function StopRemoteEdit(arg1:string,arg2:number):Promise<void>;
+
+export function StopSessionLog(arg1:string):Promise<void>;
+
+export function StopShare(arg1:string):Promise<void>;
+
 export function UploadFile(arg1:string,arg2:string,arg3:string):Promise<void>;
 
+export function WriteBroadcast(arg1:string):Promise<void>;
+
+export function WriteCommandInput(arg1:string,arg2:string):Promise<void>;
+
 export function WriteToTerminal(arg1:string,arg2:string):Promise<void>;
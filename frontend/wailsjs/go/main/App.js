@@ -2,26 +2,106 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AckTerminalData(arg1) {
+  return window['go']['main']['App']['AckTerminalData'](arg1);
+}
+
+export function AttachMultiplexer(arg1, arg2, arg3) {
+  return window['go']['main']['App']['AttachMultiplexer'](arg1, arg2, arg3);
+}
+
+export function CancelCommand(arg1) {
+  return window['go']['main']['App']['CancelCommand'](arg1);
+}
+
+export function CancelRun(arg1) {
+  return window['go']['main']['App']['CancelRun'](arg1);
+}
+
 export function CancelTransfer(arg1, arg2) {
   return window['go']['main']['App']['CancelTransfer'](arg1, arg2);
 }
 
+export function CancelZmodem(arg1) {
+  return window['go']['main']['App']['CancelZmodem'](arg1);
+}
+
+export function ChangeMode(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ChangeMode'](arg1, arg2, arg3);
+}
+
+export function ChangeOwner(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ChangeOwner'](arg1, arg2, arg3);
+}
+
 export function ClearCompletedTransfers(arg1) {
   return window['go']['main']['App']['ClearCompletedTransfers'](arg1);
 }
 
+export function ClearHistory(arg1) {
+  return window['go']['main']['App']['ClearHistory'](arg1);
+}
+
+export function ClearRun(arg1) {
+  return window['go']['main']['App']['ClearRun'](arg1);
+}
+
+export function CloseCommandInput(arg1) {
+  return window['go']['main']['App']['CloseCommandInput'](arg1);
+}
+
+export function CloseRecording(arg1) {
+  return window['go']['main']['App']['CloseRecording'](arg1);
+}
+
+export function CloseShell(arg1) {
+  return window['go']['main']['App']['CloseShell'](arg1);
+}
+
 export function Connect(arg1, arg2, arg3, arg4, arg5, arg6) {
   return window['go']['main']['App']['Connect'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
+export function ConnectLocal(arg1, arg2) {
+  return window['go']['main']['App']['ConnectLocal'](arg1, arg2);
+}
+
+export function ConnectRaw(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ConnectRaw'](arg1, arg2, arg3, arg4);
+}
+
+export function ConnectTelnet(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ConnectTelnet'](arg1, arg2, arg3, arg4);
+}
+
+export function CreateHardLink(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CreateHardLink'](arg1, arg2, arg3);
+}
+
+export function CreateSymlink(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CreateSymlink'](arg1, arg2, arg3);
+}
+
 export function DeleteRemoteFile(arg1, arg2) {
   return window['go']['main']['App']['DeleteRemoteFile'](arg1, arg2);
 }
 
+export function DeleteSecret(arg1) {
+  return window['go']['main']['App']['DeleteSecret'](arg1);
+}
+
 export function DeleteSession(arg1) {
   return window['go']['main']['App']['DeleteSession'](arg1);
 }
 
+export function DeleteSnippet(arg1) {
+  return window['go']['main']['App']['DeleteSnippet'](arg1);
+}
+
+export function DeleteTrigger(arg1) {
+  return window['go']['main']['App']['DeleteTrigger'](arg1);
+}
+
 export function DisconnectAll() {
   return window['go']['main']['App']['DisconnectAll']();
 }
@@ -34,50 +114,258 @@ export function DownloadFile(arg1, arg2, arg3) {
   return window['go']['main']['App']['DownloadFile'](arg1, arg2, arg3);
 }
 
+export function EditRemoteFile(arg1, arg2) {
+  return window['go']['main']['App']['EditRemoteFile'](arg1, arg2);
+}
+
+export function ExportRecording(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportRecording'](arg1, arg2, arg3);
+}
+
+export function ExportRunResults(arg1, arg2) {
+  return window['go']['main']['App']['ExportRunResults'](arg1, arg2);
+}
+
 export function GetActiveTunnels(arg1) {
   return window['go']['main']['App']['GetActiveTunnels'](arg1);
 }
 
+export function GetBroadcastMembers() {
+  return window['go']['main']['App']['GetBroadcastMembers']();
+}
+
+export function GetCharsets() {
+  return window['go']['main']['App']['GetCharsets']();
+}
+
 export function GetLocalWD() {
   return window['go']['main']['App']['GetLocalWD']();
 }
 
+export function GetRecordingPath(arg1) {
+  return window['go']['main']['App']['GetRecordingPath'](arg1);
+}
+
+export function GetRemoteEdits(arg1) {
+  return window['go']['main']['App']['GetRemoteEdits'](arg1);
+}
+
+export function GetRunReport(arg1) {
+  return window['go']['main']['App']['GetRunReport'](arg1);
+}
+
+export function GetScrollback(arg1) {
+  return window['go']['main']['App']['GetScrollback'](arg1);
+}
+
+export function GetSessionLogPath(arg1) {
+  return window['go']['main']['App']['GetSessionLogPath'](arg1);
+}
+
 export function GetSessionPassword(arg1) {
   return window['go']['main']['App']['GetSessionPassword'](arg1);
 }
 
+export function GetSessionSnippets(arg1) {
+  return window['go']['main']['App']['GetSessionSnippets'](arg1);
+}
+
+export function GetSettings() {
+  return window['go']['main']['App']['GetSettings']();
+}
+
+export function GetShare(arg1) {
+  return window['go']['main']['App']['GetShare'](arg1);
+}
+
+export function GetSnippetVariables(arg1) {
+  return window['go']['main']['App']['GetSnippetVariables'](arg1);
+}
+
+export function GetSnippets() {
+  return window['go']['main']['App']['GetSnippets']();
+}
+
 export function GetTransfers(arg1) {
   return window['go']['main']['App']['GetTransfers'](arg1);
 }
 
+export function GetTriggers() {
+  return window['go']['main']['App']['GetTriggers']();
+}
+
+export function GetWorkingDir(arg1) {
+  return window['go']['main']['App']['GetWorkingDir'](arg1);
+}
+
 export function GoUp(arg1, arg2) {
   return window['go']['main']['App']['GoUp'](arg1, arg2);
 }
 
-export function ListFiles(arg1, arg2) {
-  return window['go']['main']['App']['ListFiles'](arg1, arg2);
+export function KickShareViewer(arg1, arg2) {
+  return window['go']['main']['App']['KickShareViewer'](arg1, arg2);
 }
 
-export function ListLocalFiles(arg1) {
-  return window['go']['main']['App']['ListLocalFiles'](arg1);
+export function ListFiles(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ListFiles'](arg1, arg2, arg3);
+}
+
+export function ListLocalFiles(arg1, arg2) {
+  return window['go']['main']['App']['ListLocalFiles'](arg1, arg2);
+}
+
+export function ListMultiplexerSessions(arg1) {
+  return window['go']['main']['App']['ListMultiplexerSessions'](arg1);
+}
+
+export function ListShells(arg1) {
+  return window['go']['main']['App']['ListShells'](arg1);
 }
 
 export function LoadSessions() {
   return window['go']['main']['App']['LoadSessions']();
 }
 
+export function OpenRecording(arg1) {
+  return window['go']['main']['App']['OpenRecording'](arg1);
+}
+
+export function OpenShell(arg1) {
+  return window['go']['main']['App']['OpenShell'](arg1);
+}
+
+export function OverwriteRemoteEdit(arg1, arg2) {
+  return window['go']['main']['App']['OverwriteRemoteEdit'](arg1, arg2);
+}
+
+export function PlaybackPause(arg1) {
+  return window['go']['main']['App']['PlaybackPause'](arg1);
+}
+
+export function PlaybackPlay(arg1) {
+  return window['go']['main']['App']['PlaybackPlay'](arg1);
+}
+
+export function PlaybackSeek(arg1, arg2) {
+  return window['go']['main']['App']['PlaybackSeek'](arg1, arg2);
+}
+
+export function PlaybackSetSpeed(arg1, arg2) {
+  return window['go']['main']['App']['PlaybackSetSpeed'](arg1, arg2);
+}
+
+export function RealPath(arg1, arg2) {
+  return window['go']['main']['App']['RealPath'](arg1, arg2);
+}
+
+export function RecallHistory(arg1, arg2) {
+  return window['go']['main']['App']['RecallHistory'](arg1, arg2);
+}
+
+export function ReloadRemoteEdit(arg1, arg2) {
+  return window['go']['main']['App']['ReloadRemoteEdit'](arg1, arg2);
+}
+
+export function RemoveBroadcastMember(arg1) {
+  return window['go']['main']['App']['RemoveBroadcastMember'](arg1);
+}
+
 export function RenameFile(arg1, arg2, arg3) {
   return window['go']['main']['App']['RenameFile'](arg1, arg2, arg3);
 }
 
+export function ResizeCommand(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ResizeCommand'](arg1, arg2, arg3);
+}
+
 export function ResizeTerminal(arg1, arg2, arg3) {
   return window['go']['main']['App']['ResizeTerminal'](arg1, arg2, arg3);
 }
 
+export function RunParallel(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['RunParallel'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function RunSnippet(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RunSnippet'](arg1, arg2, arg3);
+}
+
+export function RunSnippetBroadcast(arg1, arg2) {
+  return window['go']['main']['App']['RunSnippetBroadcast'](arg1, arg2);
+}
+
+export function SaveCharset(arg1, arg2) {
+  return window['go']['main']['App']['SaveCharset'](arg1, arg2);
+}
+
+export function SaveCwdTracking(arg1, arg2) {
+  return window['go']['main']['App']['SaveCwdTracking'](arg1, arg2);
+}
+
+export function SaveEditor(arg1) {
+  return window['go']['main']['App']['SaveEditor'](arg1);
+}
+
+export function SaveHistoryOptOut(arg1, arg2) {
+  return window['go']['main']['App']['SaveHistoryOptOut'](arg1, arg2);
+}
+
+export function SaveLocalSession(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['SaveLocalSession'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function SaveLogSettings(arg1, arg2) {
+  return window['go']['main']['App']['SaveLogSettings'](arg1, arg2);
+}
+
+export function SaveLoginScript(arg1, arg2) {
+  return window['go']['main']['App']['SaveLoginScript'](arg1, arg2);
+}
+
+export function SaveMultiplexer(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SaveMultiplexer'](arg1, arg2, arg3, arg4);
+}
+
+export function SaveProtocol(arg1, arg2) {
+  return window['go']['main']['App']['SaveProtocol'](arg1, arg2);
+}
+
+export function SaveSecret(arg1, arg2) {
+  return window['go']['main']['App']['SaveSecret'](arg1, arg2);
+}
+
 export function SaveSession(arg1, arg2, arg3, arg4, arg5, arg6) {
   return window['go']['main']['App']['SaveSession'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
+export function SaveSessionTriggers(arg1, arg2) {
+  return window['go']['main']['App']['SaveSessionTriggers'](arg1, arg2);
+}
+
+export function SaveSnippet(arg1) {
+  return window['go']['main']['App']['SaveSnippet'](arg1);
+}
+
+export function SaveTrigger(arg1) {
+  return window['go']['main']['App']['SaveTrigger'](arg1);
+}
+
+export function SearchHistory(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SearchHistory'](arg1, arg2, arg3);
+}
+
+export function SearchHostHistory(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SearchHostHistory'](arg1, arg2, arg3);
+}
+
+export function SearchScrollback(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SearchScrollback'](arg1, arg2, arg3, arg4);
+}
+
+export function SelectRecordingFile() {
+  return window['go']['main']['App']['SelectRecordingFile']();
+}
+
 export function SelectSavePath(arg1) {
   return window['go']['main']['App']['SelectSavePath'](arg1);
 }
@@ -86,18 +374,78 @@ export function SelectUploadFile() {
   return window['go']['main']['App']['SelectUploadFile']();
 }
 
+export function SetBroadcastMemberEnabled(arg1, arg2) {
+  return window['go']['main']['App']['SetBroadcastMemberEnabled'](arg1, arg2);
+}
+
+export function SetBroadcastMembers(arg1) {
+  return window['go']['main']['App']['SetBroadcastMembers'](arg1);
+}
+
+export function SetShareInput(arg1, arg2) {
+  return window['go']['main']['App']['SetShareInput'](arg1, arg2);
+}
+
+export function SetTriggerEnabled(arg1, arg2) {
+  return window['go']['main']['App']['SetTriggerEnabled'](arg1, arg2);
+}
+
+export function SignalCommand(arg1, arg2) {
+  return window['go']['main']['App']['SignalCommand'](arg1, arg2);
+}
+
+export function StartCommand(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['StartCommand'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
 export function StartLocalForward(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['StartLocalForward'](arg1, arg2, arg3, arg4);
 }
 
+export function StartRecording(arg1, arg2) {
+  return window['go']['main']['App']['StartRecording'](arg1, arg2);
+}
+
+export function StartSessionLog(arg1, arg2) {
+  return window['go']['main']['App']['StartSessionLog'](arg1, arg2);
+}
+
+export function StartShare(arg1, arg2, arg3) {
+  return window['go']['main']['App']['StartShare'](arg1, arg2, arg3);
+}
+
 export function StopLocalForward(arg1, arg2) {
   return window['go']['main']['App']['StopLocalForward'](arg1, arg2);
 }
 
+export function StopRecording(arg1) {
+  return window['go']['main']['App']['StopRecording'](arg1);
+}
+
+export function StopRemoteEdit(arg1, arg2) {
+  return window['go']['main']['App']['StopRemoteEdit'](arg1, arg2);
+}
+
+export function StopSessionLog(arg1) {
+  return window['go']['main']['App']['StopSessionLog'](arg1);
+}
+
+export function StopShare(arg1) {
+  return window['go']['main']['App']['StopShare'](arg1);
+}
+
 export function UploadFile(arg1, arg2, arg3) {
   return window['go']['main']['App']['UploadFile'](arg1, arg2, arg3);
 }
 
+export function WriteBroadcast(arg1) {
+  return window['go']['main']['App']['WriteBroadcast'](arg1);
+}
+
+export function WriteCommandInput(arg1, arg2) {
+  return window['go']['main']['App']['WriteCommandInput'](arg1, arg2);
+}
+
 export function WriteToTerminal(arg1, arg2) {
   return window['go']['main']['App']['WriteToTerminal'](arg1, arg2);
 }
@@ -1,13 +1,123 @@
 export namespace config {
 	
+	export class LocalShell {
+	    command?: string;
+	    dir?: string;
+	    env?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new LocalShell(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.command = source["command"];
+	        this.dir = source["dir"];
+	        this.env = source["env"];
+	    }
+	}
+	export class LoginStep {
+	    action: string;
+	    pattern?: string;
+	    text?: string;
+	    secret?: string;
+	    timeout?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LoginStep(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.action = source["action"];
+	        this.pattern = source["pattern"];
+	        this.text = source["text"];
+	        this.secret = source["secret"];
+	        this.timeout = source["timeout"];
+	    }
+	}
+	export class Multiplexer {
+	    tool: string;
+	    name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Multiplexer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tool = source["tool"];
+	        this.name = source["name"];
+	    }
+	}
+	export class Trigger {
+	    id: string;
+	    name: string;
+	    pattern: string;
+	    action: string;
+	    text?: string;
+	    secret?: string;
+	    color?: string;
+	    enabled: boolean;
+	    cooldown?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Trigger(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.pattern = source["pattern"];
+	        this.action = source["action"];
+	        this.text = source["text"];
+	        this.secret = source["secret"];
+	        this.color = source["color"];
+	        this.enabled = source["enabled"];
+	        this.cooldown = source["cooldown"];
+	    }
+	}
+	export class SessionLog {
+	    auto_start: boolean;
+	    template?: string;
+	    strip_ansi?: boolean;
+	    log_input?: boolean;
+	    max_size_mb?: number;
+	    max_backups?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SessionLog(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.auto_start = source["auto_start"];
+	        this.template = source["template"];
+	        this.strip_ansi = source["strip_ansi"];
+	        this.log_input = source["log_input"];
+	        this.max_size_mb = source["max_size_mb"];
+	        this.max_backups = source["max_backups"];
+	    }
+	}
 	export class Session {
 	    name: string;
+	    protocol?: string;
 	    host: string;
 	    port: number;
 	    username: string;
 	    private_key?: string;
 	    group?: string;
 	    last_used: string;
+	    login_script?: LoginStep[];
+	    log?: SessionLog;
+	    triggers?: Trigger[];
+	    track_cwd?: boolean;
+	    no_history?: boolean;
+	    charset?: string;
+	    local?: LocalShell;
+	    multiplexer?: Multiplexer;
+	    list_multiplexers?: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new Session(source);
@@ -16,25 +126,212 @@ export namespace config {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.name = source["name"];
+	        this.protocol = source["protocol"];
 	        this.host = source["host"];
 	        this.port = source["port"];
 	        this.username = source["username"];
 	        this.private_key = source["private_key"];
 	        this.group = source["group"];
 	        this.last_used = source["last_used"];
+	        this.login_script = this.convertValues(source["login_script"], LoginStep);
+	        this.log = this.convertValues(source["log"], SessionLog);
+	        this.triggers = this.convertValues(source["triggers"], Trigger);
+	        this.track_cwd = source["track_cwd"];
+	        this.no_history = source["no_history"];
+	        this.charset = source["charset"];
+	        this.local = this.convertValues(source["local"], LocalShell);
+	        this.multiplexer = this.convertValues(source["multiplexer"], Multiplexer);
+	        this.list_multiplexers = source["list_multiplexers"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class Settings {
+	    editor?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.editor = source["editor"];
+	    }
+	}
+	export class Snippet {
+	    id: string;
+	    name: string;
+	    body?: string;
+	    tags?: string[];
+	    group?: string;
+	    steps?: LoginStep[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Snippet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.body = source["body"];
+	        this.tags = source["tags"];
+	        this.group = source["group"];
+	        this.steps = this.convertValues(source["steps"], LoginStep);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace history {
+	
+	export class Entry {
+	    command: string;
+	    // Go type: time
+	    time: any;
+	    exit_code?: number;
+	    dir?: string;
+	    session?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.command = source["command"];
+	        this.time = this.convertValues(source["time"], null);
+	        this.exit_code = source["exit_code"];
+	        this.dir = source["dir"];
+	        this.session = source["session"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
 
 export namespace main {
 	
+	export class BroadcastMember {
+	    id: string;
+	    enabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BroadcastMember(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.enabled = source["enabled"];
+	    }
+	}
+	export class ChmodOptions {
+	    file_mode: string;
+	    dir_mode: string;
+	    recursive: boolean;
+	    dry_run: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChmodOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file_mode = source["file_mode"];
+	        this.dir_mode = source["dir_mode"];
+	        this.recursive = source["recursive"];
+	        this.dry_run = source["dry_run"];
+	    }
+	}
+	export class ChownOptions {
+	    owner: string;
+	    group: string;
+	    recursive: boolean;
+	    dry_run: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChownOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.owner = source["owner"];
+	        this.group = source["group"];
+	        this.recursive = source["recursive"];
+	        this.dry_run = source["dry_run"];
+	    }
+	}
 	export class FileItem {
 	    name: string;
 	    size: string;
 	    mode: string;
 	    time: string;
 	    is_dir: boolean;
+	    bytes: number;
+	    mtime: number;
+	    perm: string;
+	    uid: number;
+	    gid: number;
+	    owner?: string;
+	    group?: string;
+	    hidden: boolean;
+	    is_link: boolean;
+	    link_target?: string;
+	    link_type?: string;
+	    broken?: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new FileItem(source);
@@ -47,6 +344,130 @@ export namespace main {
 	        this.mode = source["mode"];
 	        this.time = source["time"];
 	        this.is_dir = source["is_dir"];
+	        this.bytes = source["bytes"];
+	        this.mtime = source["mtime"];
+	        this.perm = source["perm"];
+	        this.uid = source["uid"];
+	        this.gid = source["gid"];
+	        this.owner = source["owner"];
+	        this.group = source["group"];
+	        this.hidden = source["hidden"];
+	        this.is_link = source["is_link"];
+	        this.link_target = source["link_target"];
+	        this.link_type = source["link_type"];
+	        this.broken = source["broken"];
+	    }
+	}
+	export class ListOptions {
+	    sort_by: string;
+	    desc: boolean;
+	    mix_dirs: boolean;
+	    filter: string;
+	    hide_hidden: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ListOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sort_by = source["sort_by"];
+	        this.desc = source["desc"];
+	        this.mix_dirs = source["mix_dirs"];
+	        this.filter = source["filter"];
+	        this.hide_hidden = source["hide_hidden"];
+	    }
+	}
+	export class PlaybackInfo {
+	    id: string;
+	    title: string;
+	    width: number;
+	    height: number;
+	    duration: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PlaybackInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.duration = source["duration"];
+	    }
+	}
+	export class RemoteEdit {
+	    id: number;
+	    remote_path: string;
+	    local_path: string;
+	    status: string;
+	    message?: string;
+	    uploads: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RemoteEdit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.remote_path = source["remote_path"];
+	        this.local_path = source["local_path"];
+	        this.status = source["status"];
+	        this.message = source["message"];
+	        this.uploads = source["uploads"];
+	    }
+	}
+	export class ShareInfo {
+	    url: string;
+	    allow_input: boolean;
+	    viewers: share.Viewer[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ShareInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.allow_input = source["allow_input"];
+	        this.viewers = this.convertValues(source["viewers"], share.Viewer);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ShellInfo {
+	    id: string;
+	    rows: number;
+	    cols: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ShellInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.rows = source["rows"];
+	        this.cols = source["cols"];
 	    }
 	}
 	export class TunnelInfo {
@@ -70,6 +491,191 @@ export namespace main {
 
 }
 
+export namespace multiexec {
+	
+	export class Group {
+	    hosts: string[];
+	    stdout: string;
+	    stderr: string;
+	    exit_code: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Group(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hosts = source["hosts"];
+	        this.stdout = source["stdout"];
+	        this.stderr = source["stderr"];
+	        this.exit_code = source["exit_code"];
+	        this.error = source["error"];
+	    }
+	}
+	export class HostResult {
+	    name: string;
+	    host: string;
+	    stdout: string;
+	    stderr: string;
+	    exit_code: number;
+	    error?: string;
+	    truncated?: boolean;
+	    start_time: string;
+	    duration_ms: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HostResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.host = source["host"];
+	        this.stdout = source["stdout"];
+	        this.stderr = source["stderr"];
+	        this.exit_code = source["exit_code"];
+	        this.error = source["error"];
+	        this.truncated = source["truncated"];
+	        this.start_time = source["start_time"];
+	        this.duration_ms = source["duration_ms"];
+	    }
+	}
+	export class Report {
+	    command: string;
+	    start_time: string;
+	    end_time: string;
+	    results: HostResult[];
+	    groups: Group[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.command = source["command"];
+	        this.start_time = source["start_time"];
+	        this.end_time = source["end_time"];
+	        this.results = this.convertValues(source["results"], HostResult);
+	        this.groups = this.convertValues(source["groups"], Group);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace mux {
+	
+	export class Session {
+	    tool: string;
+	    name: string;
+	    windows?: number;
+	    attached: boolean;
+	    created?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Session(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tool = source["tool"];
+	        this.name = source["name"];
+	        this.windows = source["windows"];
+	        this.attached = source["attached"];
+	        this.created = source["created"];
+	    }
+	}
+
+}
+
+export namespace sftp {
+	
+	export class Change {
+	    path: string;
+	    from: string;
+	    to: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Change(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.error = source["error"];
+	    }
+	}
+
+}
+
+export namespace share {
+	
+	export class Viewer {
+	    id: number;
+	    name: string;
+	    addr: string;
+	    joined: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Viewer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.addr = source["addr"];
+	        this.joined = source["joined"];
+	    }
+	}
+
+}
+
+export namespace terminal {
+	
+	export class Match {
+	    line: number;
+	    start: number;
+	    end: number;
+	    text: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Match(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.line = source["line"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.text = source["text"];
+	    }
+	}
+
+}
+
 export namespace transfer {
 	
 	export class TransferItem {
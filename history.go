@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+
+	"Genpilot/internal/history"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// recordCommand stores a command run in a shell under the session's host
+func (a *App) recordCommand(state *SessionState, ch *ShellChannel, e history.Entry) {
+	a.sessionsLock.RLock()
+	disabled := state.Config.NoHistory
+	a.sessionsLock.RUnlock()
+	e.Dir = ch.workingDir()
+
+	if disabled || a.history == nil {
+		return
+	}
+
+	e.Session = state.Name
+	if err := a.history.Add(state.Host, e); err != nil {
+		runtime.LogError(a.ctx, "History failed for "+state.Host+": "+err.Error())
+		return
+	}
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "history-"+ch.ID, e)
+	}
+}
+
+// SearchHistory searches the command history of the host a terminal is
+// connected to, newest first
+func (a *App) SearchHistory(id, query string, limit int) ([]history.Entry, error) {
+	a.sessionsLock.RLock()
+	var host string
+	if ch, ok := a.shells[id]; ok {
+		host = a.sessions[ch.SessionID].Host
+	}
+	a.sessionsLock.RUnlock()
+
+	if host == "" {
+		return nil, fmt.Errorf("terminal %s not connected", id)
+	}
+	return a.SearchHostHistory(host, query, limit)
+}
+
+// SearchHostHistory searches the command history of a host, newest first
+func (a *App) SearchHostHistory(host, query string, limit int) ([]history.Entry, error) {
+	if a.history == nil {
+		return nil, fmt.Errorf("history storage unavailable")
+	}
+	return a.history.Search(host, query, limit)
+}
+
+// RecallHistory types a command into a terminal without running it
+func (a *App) RecallHistory(id, command string) {
+	a.WriteToTerminal(id, command)
+}
+
+// ClearHistory deletes the command history of a host
+func (a *App) ClearHistory(host string) error {
+	if a.history == nil {
+		return nil
+	}
+	return a.history.Clear(host)
+}
+
+// SaveHistoryOptOut keeps a saved session's commands out of the history,
+// starting with the next command on any open connection
+func (a *App) SaveHistoryOptOut(name string, disabled bool) error {
+	if err := a.sessionMgr.SetNoHistory(name, disabled); err != nil {
+		return err
+	}
+
+	a.sessionsLock.Lock()
+	for _, s := range a.sessions {
+		if s.Name == name {
+			s.Config.NoHistory = disabled
+		}
+	}
+	a.sessionsLock.Unlock()
+	return nil
+}
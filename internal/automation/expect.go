@@ -0,0 +1,88 @@
+package automation
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+
+	"Genpilot/internal/terminal"
+)
+
+// ErrClosed is returned when the output stream ends while waiting
+var ErrClosed = errors.New("output stream closed")
+
+const maxExpectBuffer = 64 * 1024
+
+// Expecter collects terminal output so script steps can wait for patterns.
+// Escape sequences are stripped, so patterns match the visible text.
+type Expecter struct {
+	mu       sync.Mutex
+	buf      []byte
+	stripper terminal.Stripper
+	closed   bool
+	signal   chan struct{} // closed and replaced on every write
+}
+
+// NewExpecter creates an empty expecter
+func NewExpecter() *Expecter {
+	return &Expecter{signal: make(chan struct{})}
+}
+
+// Write appends output to the buffer and wakes up waiters
+func (e *Expecter) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return len(p), nil
+	}
+
+	e.buf = append(e.buf, e.stripper.Strip(p)...)
+	if len(e.buf) > maxExpectBuffer {
+		e.buf = append([]byte(nil), e.buf[len(e.buf)-maxExpectBuffer:]...)
+	}
+
+	close(e.signal)
+	e.signal = make(chan struct{})
+	return len(p), nil
+}
+
+// Close releases the buffer and fails pending and future Expect calls
+func (e *Expecter) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return
+	}
+	e.closed = true
+	e.buf = nil
+	close(e.signal)
+}
+
+// Expect blocks until re matches the buffered output and returns the match.
+// Everything up to the end of the match is consumed.
+func (e *Expecter) Expect(ctx context.Context, re *regexp.Regexp) (string, error) {
+	for {
+		e.mu.Lock()
+		if e.closed {
+			e.mu.Unlock()
+			return "", ErrClosed
+		}
+		if loc := re.FindIndex(e.buf); loc != nil {
+			match := string(e.buf[loc[0]:loc[1]])
+			e.buf = e.buf[loc[1]:]
+			e.mu.Unlock()
+			return match, nil
+		}
+		wait := e.signal
+		e.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package automation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"Genpilot/internal/config"
+)
+
+// DefaultTimeout applies to expect steps that don't set their own
+const DefaultTimeout = 10 * time.Second
+
+// ErrTimeout is returned when an expect step doesn't match in time
+var ErrTimeout = errors.New("timed out")
+
+// lookupSecret resolves secret steps; replaced in tests
+var lookupSecret = config.GetSecret
+
+// StepError reports which step of a script failed
+type StepError struct {
+	Index int
+	Step  config.LoginStep
+	Err   error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("step %d (%s): %v", e.Index+1, e.Step.Action, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Run executes steps in order, waiting on output from exp and sending input to w
+func Run(ctx context.Context, steps []config.LoginStep, exp *Expecter, w io.Writer) error {
+	for i, step := range steps {
+		if err := runStep(ctx, step, exp, w); err != nil {
+			return &StepError{Index: i, Step: step, Err: err}
+		}
+	}
+	return nil
+}
+
+func runStep(ctx context.Context, step config.LoginStep, exp *Expecter, w io.Writer) error {
+	timeout := time.Duration(step.Timeout) * time.Millisecond
+
+	switch step.Action {
+	case config.StepExpect:
+		re, err := regexp.Compile(step.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		stepCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		if _, err := exp.Expect(stepCtx, re); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				return fmt.Errorf("%w after %s waiting for %q", ErrTimeout, timeout, step.Pattern)
+			}
+			return err
+		}
+		return nil
+
+	case config.StepSend:
+		_, err := io.WriteString(w, step.Text)
+		return err
+
+	case config.StepSecret:
+		secret, err := lookupSecret(step.Secret)
+		if err != nil {
+			return fmt.Errorf("secret %q: %w", step.Secret, err)
+		}
+		_, err = io.WriteString(w, secret+"\r")
+		return err
+
+	case config.StepSleep:
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return fmt.Errorf("unknown action %q", step.Action)
+}
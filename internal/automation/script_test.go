@@ -0,0 +1,55 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"Genpilot/internal/config"
+)
+
+func TestRunScript(t *testing.T) {
+	lookupSecret = func(name string) (string, error) {
+		return "s3cret", nil
+	}
+	defer func() { lookupSecret = config.GetSecret }()
+
+	exp := NewExpecter()
+	var input bytes.Buffer
+
+	// Prompts arrive colored and split across reads
+	exp.Write([]byte("Last login: today\r\n\x1b[01;32muser@host\x1b[0"))
+	exp.Write([]byte("0m:~$ "))
+
+	steps := []config.LoginStep{
+		{Action: config.StepExpect, Pattern: `user@host:~\$ $`},
+		{Action: config.StepSend, Text: "sudo -i\r"},
+		{Action: config.StepSecret, Secret: "root"},
+	}
+	if err := Run(context.Background(), steps, exp, &input); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got := input.String(); got != "sudo -i\rs3cret\r" {
+		t.Errorf("Unexpected input %q", got)
+	}
+}
+
+func TestRunScriptTimeout(t *testing.T) {
+	exp := NewExpecter()
+	exp.Write([]byte("Password: "))
+
+	steps := []config.LoginStep{
+		{Action: config.StepSend, Text: "cd /srv/app\r"},
+		{Action: config.StepExpect, Pattern: `\$ $`, Timeout: 20},
+	}
+	err := Run(context.Background(), steps, exp, &bytes.Buffer{})
+
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Index != 1 {
+		t.Fatalf("Expected failure at step 2, got %v", err)
+	}
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected timeout, got %v", err)
+	}
+}
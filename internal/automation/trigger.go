@@ -0,0 +1,147 @@
+package automation
+
+import (
+	"errors"
+	"regexp"
+	"sync"
+	"time"
+
+	"Genpilot/internal/config"
+	"Genpilot/internal/terminal"
+)
+
+// DefaultCooldown applies to triggers that don't set their own
+const DefaultCooldown = 2 * time.Second
+
+// Longer lines only keep their end, which is where prompts are
+const maxTriggerLine = 4096
+
+// Firing is a trigger that matched the output
+type Firing struct {
+	Trigger config.Trigger
+	Match   string // the matched text
+	Line    string // the visible line the match was found in
+	Start   int    // byte offsets of the match within Line
+	End     int
+}
+
+type triggerRule struct {
+	config.Trigger
+	re *regexp.Regexp
+}
+
+// Triggers matches rules against terminal output line by line, with escape
+// sequences stripped. The line being written is checked as output arrives,
+// so prompts without a trailing newline match too. Each rule fires at most
+// once per line and then waits out its cooldown.
+type Triggers struct {
+	mu       sync.Mutex
+	rules    []triggerRule
+	last     []time.Time // when each rule last fired
+	stripper terminal.Stripper
+	line     []byte
+	fired    map[int]bool // rules that already matched the current line
+	fire     func(Firing)
+	now      func() time.Time
+}
+
+// NewTriggers creates a matcher that passes firings to fire
+func NewTriggers(fire func(Firing)) *Triggers {
+	return &Triggers{
+		fired: make(map[int]bool),
+		fire:  fire,
+		now:   time.Now,
+	}
+}
+
+// SetRules replaces the rules. Disabled rules are left out; invalid ones
+// are skipped and reported in the returned error.
+func (t *Triggers) SetRules(rules []config.Trigger) error {
+	var compiled []triggerRule
+	var errs []error
+	for _, r := range rules {
+		if !r.Enabled {
+			continue
+		}
+		if err := r.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		compiled = append(compiled, triggerRule{Trigger: r, re: regexp.MustCompile(r.Pattern)})
+	}
+
+	t.mu.Lock()
+	t.rules = compiled
+	t.last = make([]time.Time, len(compiled))
+	t.fired = make(map[int]bool)
+	t.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// Write scans output for matches
+func (t *Triggers) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	if len(t.rules) == 0 {
+		t.mu.Unlock()
+		return len(p), nil
+	}
+
+	var firings []Firing
+	for _, c := range t.stripper.Strip(p) {
+		switch c {
+		case '\n':
+			firings = t.match(firings)
+			t.line = t.line[:0]
+			clear(t.fired)
+		case '\r':
+		default:
+			t.line = append(t.line, c)
+		}
+	}
+	if len(t.line) > maxTriggerLine {
+		t.line = append(t.line[:0], t.line[len(t.line)-maxTriggerLine:]...)
+	}
+	if len(t.line) > 0 {
+		firings = t.match(firings)
+	}
+	t.mu.Unlock()
+
+	for _, f := range firings {
+		t.fire(f)
+	}
+	return len(p), nil
+}
+
+// match checks the current line against rules that haven't matched it yet
+func (t *Triggers) match(firings []Firing) []Firing {
+	now := t.now()
+	for i, r := range t.rules {
+		if t.fired[i] {
+			continue
+		}
+		loc := r.re.FindIndex(t.line)
+		if loc == nil {
+			continue
+		}
+		t.fired[i] = true
+
+		cooldown := DefaultCooldown
+		if r.Cooldown > 0 {
+			cooldown = time.Duration(r.Cooldown) * time.Second
+		}
+		if !t.last[i].IsZero() && now.Sub(t.last[i]) < cooldown {
+			continue
+		}
+		t.last[i] = now
+
+		line := string(t.line)
+		firings = append(firings, Firing{
+			Trigger: r.Trigger,
+			Match:   line[loc[0]:loc[1]],
+			Line:    line,
+			Start:   loc[0],
+			End:     loc[1],
+		})
+	}
+	return firings
+}
@@ -0,0 +1,49 @@
+package automation
+
+import (
+	"testing"
+	"time"
+
+	"Genpilot/internal/config"
+)
+
+func TestTriggers(t *testing.T) {
+	var firings []Firing
+	tr := NewTriggers(func(f Firing) { firings = append(firings, f) })
+	now := time.Unix(0, 0)
+	tr.now = func() time.Time { return now }
+
+	err := tr.SetRules([]config.Trigger{
+		{Name: "sudo", Pattern: `\[sudo\] password for \w+:`, Action: config.TriggerSecret, Secret: "root", Enabled: true},
+		{Name: "failed", Pattern: `FAILED`, Action: config.TriggerHighlight, Enabled: true, Cooldown: 10},
+		{Name: "off", Pattern: `.`, Action: config.TriggerAlert},
+		{Name: "broken", Pattern: `(`, Action: config.TriggerNotify, Enabled: true},
+	})
+	if err == nil {
+		t.Error("Invalid rule was not reported")
+	}
+
+	// A prompt split across reads matches before any newline arrives,
+	// and only once even when the rest of the line follows
+	tr.Write([]byte("[sudo] pass"))
+	tr.Write([]byte("word for bob: "))
+	tr.Write([]byte("\r\n"))
+	if len(firings) != 1 || firings[0].Trigger.Name != "sudo" {
+		t.Fatalf("Unexpected firings %+v", firings)
+	}
+
+	// Matches are found in the visible text and located within the line
+	tr.Write([]byte("make: \x1b[31mFAILED\x1b[0m target\r\n"))
+	if len(firings) != 2 || firings[1].Line != "make: FAILED target" || firings[1].Start != 6 || firings[1].End != 12 {
+		t.Fatalf("Unexpected highlight %+v", firings[len(firings)-1])
+	}
+
+	// Rate limited until the cooldown passes
+	now = now.Add(5 * time.Second)
+	tr.Write([]byte("FAILED again\n"))
+	now = now.Add(6 * time.Second)
+	tr.Write([]byte("FAILED once more\n"))
+	if len(firings) != 3 || firings[2].Line != "FAILED once more" {
+		t.Errorf("Cooldown not applied: %+v", firings)
+	}
+}
@@ -0,0 +1,42 @@
+// Package cmdline splits user-entered command lines into arguments
+package cmdline
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Split splits a command line on spaces. Double quotes group words
+// so paths like "C:\Program Files\editor.exe" stay whole; backslashes are
+// kept as they are.
+func Split(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inQuote, inArg := false, false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			inArg = true
+		case (r == ' ' || r == '\t') && !inQuote:
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return args, nil
+}
@@ -0,0 +1,30 @@
+package cmdline
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"code --wait", []string{"code", "--wait"}},
+		{`"C:\Program Files\Notepad++\notepad++.exe" -multiInst`, []string{`C:\Program Files\Notepad++\notepad++.exe`, "-multiInst"}},
+		{"  subl   -n  ", []string{"subl", "-n"}},
+		{`gvim "" -f`, []string{"gvim", "", "-f"}},
+	}
+	for _, tt := range tests {
+		got, err := Split(tt.in)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Split(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "   ", `code "unterminated`} {
+		if _, err := Split(bad); err == nil {
+			t.Errorf("Split(%q) succeeded", bad)
+		}
+	}
+}
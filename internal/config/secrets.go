@@ -0,0 +1,24 @@
+package config
+
+import (
+	"github.com/zalando/go-keyring"
+)
+
+// Secrets share the session keyring service; the prefix keeps them apart
+// from session passwords, which are keyed by the bare session name.
+const secretPrefix = "secret:"
+
+// SetSecret stores a named secret in the OS keyring
+func SetSecret(name, value string) error {
+	return keyring.Set("Genpilot", secretPrefix+name, value)
+}
+
+// GetSecret reads a named secret from the OS keyring
+func GetSecret(name string) (string, error) {
+	return keyring.Get("Genpilot", secretPrefix+name)
+}
+
+// DeleteSecret removes a named secret from the OS keyring
+func DeleteSecret(name string) error {
+	return keyring.Delete("Genpilot", secretPrefix+name)
+}
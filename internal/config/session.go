@@ -2,16 +2,27 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/zalando/go-keyring"
 )
 
+// Session protocols
+const (
+	ProtocolSSH    = "ssh"    // the default when Protocol is empty
+	ProtocolLocal  = "local"  // a shell on this machine
+	ProtocolTelnet = "telnet" // a telnet server
+	ProtocolRaw    = "raw"    // a plain TCP socket
+)
+
 // Session represents a saved SSH connection configuration
 type Session struct {
 	Name       string `json:"name"`
+	Protocol   string `json:"protocol,omitempty"`
 	Host       string `json:"host"`
 	Port       int    `json:"port"`
 	Username   string `json:"username"`
@@ -19,10 +30,80 @@ type Session struct {
 	Password   string `json:"-"` // Stored in keyring, not JSON
 	Group      string `json:"group,omitempty"`
 	LastUsed   string `json:"last_used"`
+
+	// LoginScript runs against the shell right after it starts
+	LoginScript []LoginStep `json:"login_script,omitempty"`
+
+	// Log configures terminal logging for this session
+	Log *SessionLog `json:"log,omitempty"`
+
+	// Triggers apply to this session in addition to the global ones
+	Triggers []Trigger `json:"triggers,omitempty"`
+
+	// TrackCwd installs a prompt hook so the shell reports its directory
+	TrackCwd bool `json:"track_cwd,omitempty"`
+
+	// NoHistory keeps commands run on this session out of the command history
+	NoHistory bool `json:"no_history,omitempty"`
+
+	// Charset is the host's character set when it doesn't use UTF-8
+	Charset string `json:"charset,omitempty"`
+
+	// Local configures the shell of a local session
+	Local *LocalShell `json:"local,omitempty"`
+
+	// Multiplexer makes the first shell attach to a tmux or screen session,
+	// creating it if needed, so reconnecting picks up where it left off
+	Multiplexer *Multiplexer `json:"multiplexer,omitempty"`
+
+	// ListMultiplexers looks for tmux and screen sessions after connecting
+	ListMultiplexers bool `json:"list_multiplexers,omitempty"`
+}
+
+// Multiplexer names a tmux or screen session
+type Multiplexer struct {
+	Tool string `json:"tool"` // "tmux" or "screen"
+	Name string `json:"name"`
+}
+
+// LocalShell configures a shell started on this machine
+type LocalShell struct {
+	Command string   `json:"command,omitempty"` // the user's shell if empty
+	Dir     string   `json:"dir,omitempty"`     // the home directory if empty
+	Env     []string `json:"env,omitempty"`     // KEY=VALUE pairs
+}
+
+// SessionLog configures terminal logging
+type SessionLog struct {
+	AutoStart bool   `json:"auto_start"`
+	Template  string `json:"template,omitempty"` // file name with {name}, {host}, {user}, {date}, {time}
+	StripANSI bool   `json:"strip_ansi,omitempty"`
+	LogInput  bool   `json:"log_input,omitempty"`
+	MaxSizeMB int    `json:"max_size_mb,omitempty"` // rotate at this size, 0 disables
+	// MaxBackups is how many rotated logs are kept, 0 for the default
+	MaxBackups int `json:"max_backups,omitempty"`
+}
+
+// LoginStep actions
+const (
+	StepExpect = "expect" // wait for Pattern to appear in the output
+	StepSend   = "send"   // send Text verbatim (include "\r" to press Enter)
+	StepSecret = "secret" // send the keyring secret named Secret followed by Enter
+	StepSleep  = "sleep"  // pause for Timeout milliseconds
+)
+
+// LoginStep is a single action of a post-login script
+type LoginStep struct {
+	Action  string `json:"action"`
+	Pattern string `json:"pattern,omitempty"`
+	Text    string `json:"text,omitempty"`
+	Secret  string `json:"secret,omitempty"`
+	Timeout int    `json:"timeout,omitempty"` // milliseconds
 }
 
 // SessionManager handles saving and loading sessions
 type SessionManager struct {
+	mu         sync.Mutex
 	configPath string
 	sessions   []Session
 }
@@ -52,6 +133,13 @@ func NewSessionManager() (*SessionManager, error) {
 
 // Save saves all sessions to disk
 func (sm *SessionManager) Save() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.save()
+}
+
+// save writes the sessions with sm.mu held
+func (sm *SessionManager) save() error {
 	data, err := json.MarshalIndent(sm.sessions, "", "  ")
 	if err != nil {
 		return err
@@ -61,6 +149,9 @@ func (sm *SessionManager) Save() error {
 
 // Load loads sessions from disk
 func (sm *SessionManager) Load() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
 	data, err := os.ReadFile(sm.configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -74,6 +165,9 @@ func (sm *SessionManager) Load() error {
 
 // AddSession adds a new session or updates existing one
 func (sm *SessionManager) AddSession(session Session) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
 	session.LastUsed = time.Now().Format(time.RFC3339)
 
 	// Check if session with same name exists
@@ -86,7 +180,7 @@ func (sm *SessionManager) AddSession(session Session) error {
 				_ = keyring.Set("Genpilot", session.Name, session.Password)
 			}
 
-			return sm.Save()
+			return sm.save()
 		}
 	}
 
@@ -100,44 +194,129 @@ func (sm *SessionManager) AddSession(session Session) error {
 		_ = keyring.Set("Genpilot", session.Name, session.Password)
 	}
 
-	return sm.Save()
+	return sm.save()
 }
 
 // GetSession retrieves a session by name
 func (sm *SessionManager) GetSession(name string) *Session {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
 	for i, s := range sm.sessions {
 		if s.Name == name {
 			sm.sessions[i].LastUsed = time.Now().Format(time.RFC3339)
-			sm.Save()
+			sm.save()
 			return &sm.sessions[i]
 		}
 	}
 	return nil
 }
 
+// FindSession returns a copy of a session by name without touching LastUsed
+func (sm *SessionManager) FindSession(name string) (Session, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for _, s := range sm.sessions {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Session{}, false
+}
+
+// update applies fn to the saved session called name and saves
+func (sm *SessionManager) update(name string, fn func(*Session)) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for i := range sm.sessions {
+		if sm.sessions[i].Name == name {
+			fn(&sm.sessions[i])
+			return sm.save()
+		}
+	}
+	return fmt.Errorf("session %s not found", name)
+}
+
+// SetLoginScript replaces the login script of a saved session
+func (sm *SessionManager) SetLoginScript(name string, steps []LoginStep) error {
+	return sm.update(name, func(s *Session) { s.LoginScript = steps })
+}
+
+// SetLogSettings replaces the terminal logging settings of a saved session
+func (sm *SessionManager) SetLogSettings(name string, log *SessionLog) error {
+	return sm.update(name, func(s *Session) { s.Log = log })
+}
+
+// SetTriggers replaces the triggers of a saved session
+func (sm *SessionManager) SetTriggers(name string, triggers []Trigger) error {
+	for _, t := range triggers {
+		if err := t.Validate(); err != nil {
+			return err
+		}
+	}
+	return sm.update(name, func(s *Session) { s.Triggers = triggers })
+}
+
+// SetTrackCwd turns the working directory prompt hook on or off for a saved session
+func (sm *SessionManager) SetTrackCwd(name string, enabled bool) error {
+	return sm.update(name, func(s *Session) { s.TrackCwd = enabled })
+}
+
+// SetNoHistory turns command history off or back on for a saved session
+func (sm *SessionManager) SetNoHistory(name string, disabled bool) error {
+	return sm.update(name, func(s *Session) { s.NoHistory = disabled })
+}
+
+// SetCharset sets the character set a saved session's host uses
+func (sm *SessionManager) SetCharset(name, charset string) error {
+	return sm.update(name, func(s *Session) { s.Charset = charset })
+}
+
+// SetMultiplexer sets the tmux or screen session a saved session attaches
+// to, nil for none, and whether to list sessions after connecting
+func (sm *SessionManager) SetMultiplexer(name string, m *Multiplexer, list bool) error {
+	return sm.update(name, func(s *Session) {
+		s.Multiplexer = m
+		s.ListMultiplexers = list
+	})
+}
+
+// SetProtocol sets how a saved session connects
+func (sm *SessionManager) SetProtocol(name, protocol string) error {
+	return sm.update(name, func(s *Session) { s.Protocol = protocol })
+}
+
 // DeleteSession removes a session by name
 func (sm *SessionManager) DeleteSession(name string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
 	for i, s := range sm.sessions {
 		if s.Name == name {
 			sm.sessions = append(sm.sessions[:i], sm.sessions[i+1:]...)
 			// Delete password from keyring
 			_ = keyring.Delete("Genpilot", name)
-			return sm.Save()
+			return sm.save()
 		}
 	}
 	return nil
 }
 
-// GetAllSessions returns all saved sessions
+// GetAllSessions returns a copy of all saved sessions
 func (sm *SessionManager) GetAllSessions() []Session {
-	return sm.sessions
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sessions := make([]Session, len(sm.sessions))
+	copy(sessions, sm.sessions)
+	return sessions
 }
 
 // GetRecentSessions returns sessions sorted by last used
 func (sm *SessionManager) GetRecentSessions(limit int) []Session {
 	// Sort by last used (most recent first)
-	sessions := make([]Session, len(sm.sessions))
-	copy(sessions, sm.sessions)
+	sessions := sm.GetAllSessions()
 
 	for i := 0; i < len(sessions)-1; i++ {
 		for j := i + 1; j < len(sessions); j++ {
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSessionUpdate(t *testing.T) {
+	sm := &SessionManager{
+		configPath: filepath.Join(t.TempDir(), "sessions.json"),
+		sessions:   []Session{{Name: "web"}},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := sm.SetCharset("web", "latin1"); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			sm.FindSession("web")
+		}()
+	}
+	wg.Wait()
+
+	if err := sm.SetTrackCwd("db", true); err == nil {
+		t.Error("updated a session that doesn't exist")
+	}
+
+	loaded := &SessionManager{configPath: sm.configPath}
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := loaded.FindSession("web"); !ok || s.Charset != "latin1" {
+		t.Errorf("saved session = %+v, %v", s, ok)
+	}
+}
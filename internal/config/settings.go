@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Settings are application-wide preferences
+type Settings struct {
+	// Editor opens remote files for editing, e.g. "code --wait" or
+	// "notepad++.exe". The file is appended unless {file} marks where it
+	// goes. Empty uses the system's default application.
+	Editor string `json:"editor,omitempty"`
+}
+
+// SettingsManager handles saving and loading settings
+type SettingsManager struct {
+	configPath string
+	settings   Settings
+}
+
+// NewSettingsManager creates a new settings manager
+func NewSettingsManager() (*SettingsManager, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	configDir := filepath.Join(homeDir, ".genpilot")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return nil, err
+	}
+
+	sm := &SettingsManager{
+		configPath: filepath.Join(configDir, "settings.json"),
+	}
+
+	sm.Load()
+	return sm, nil
+}
+
+// Save saves settings to disk
+func (sm *SettingsManager) Save() error {
+	data, err := json.MarshalIndent(sm.settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(sm.configPath, data, 0600)
+}
+
+// Load loads settings from disk
+func (sm *SettingsManager) Load() error {
+	data, err := os.ReadFile(sm.configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(data, &sm.settings)
+}
+
+// Get returns the current settings
+func (sm *SettingsManager) Get() Settings {
+	return sm.settings
+}
+
+// SetEditor sets the command used to edit remote files
+func (sm *SettingsManager) SetEditor(editor string) error {
+	sm.settings.Editor = editor
+	return sm.Save()
+}
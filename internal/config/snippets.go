@@ -0,0 +1,206 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Snippet is a saved command, or a macro when it has steps. ${name}
+// placeholders in the body and in step text and patterns are filled in
+// before it runs; write $${name} for a literal ${name}.
+type Snippet struct {
+	ID    string      `json:"id"`
+	Name  string      `json:"name"`
+	Body  string      `json:"body,omitempty"`
+	Tags  []string    `json:"tags,omitempty"`
+	Group string      `json:"group,omitempty"` // only offered for sessions in this group, empty for all
+	Steps []LoginStep `json:"steps,omitempty"` // macro steps; Body is not used when set
+}
+
+var placeholder = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Variables returns the placeholder names used by the snippet, in order of first use
+func (s Snippet) Variables() []string {
+	texts := []string{s.Body}
+	for _, step := range s.Steps {
+		texts = append(texts, step.Text, step.Pattern)
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, m := range placeholder.FindAllStringSubmatch(text, -1) {
+			if strings.HasPrefix(m[0], "$$") || seen[m[1]] {
+				continue
+			}
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// Expand returns the snippet with its placeholders filled in from vars.
+// Values are matched literally in expect patterns.
+func (s Snippet) Expand(vars map[string]string) (Snippet, error) {
+	var err error
+	s.Body, err = expandText(s.Body, vars, false)
+	if err != nil {
+		return s, err
+	}
+
+	steps := make([]LoginStep, len(s.Steps))
+	for i, step := range s.Steps {
+		if step.Text, err = expandText(step.Text, vars, false); err != nil {
+			return s, err
+		}
+		if step.Pattern, err = expandText(step.Pattern, vars, true); err != nil {
+			return s, err
+		}
+		steps[i] = step
+	}
+	s.Steps = steps
+	return s, nil
+}
+
+func expandText(text string, vars map[string]string, quote bool) (string, error) {
+	var missing []string
+	out := placeholder.ReplaceAllStringFunc(text, func(m string) string {
+		if strings.HasPrefix(m, "$$") {
+			return m[1:]
+		}
+		name := m[2 : len(m)-1]
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+			return m
+		}
+		if quote {
+			value = regexp.QuoteMeta(value)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing value for %s", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// SnippetManager handles saving and loading snippets
+type SnippetManager struct {
+	configPath string
+	snippets   []Snippet
+}
+
+// NewSnippetManager creates a new snippet manager
+func NewSnippetManager() (*SnippetManager, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	configDir := filepath.Join(homeDir, ".genpilot")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return nil, err
+	}
+
+	sm := &SnippetManager{
+		configPath: filepath.Join(configDir, "snippets.json"),
+		snippets:   make([]Snippet, 0),
+	}
+
+	sm.Load()
+	return sm, nil
+}
+
+// Save saves snippets to disk
+func (sm *SnippetManager) Save() error {
+	data, err := json.MarshalIndent(sm.snippets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(sm.configPath, data, 0600)
+}
+
+// Load loads snippets from disk
+func (sm *SnippetManager) Load() error {
+	data, err := os.ReadFile(sm.configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(data, &sm.snippets)
+}
+
+// Set adds a snippet or replaces the one with the same ID and returns it.
+// New snippets get an ID assigned.
+func (sm *SnippetManager) Set(s Snippet) (Snippet, error) {
+	if s.Name == "" {
+		return s, fmt.Errorf("snippet has no name")
+	}
+	for _, step := range s.Steps {
+		if step.Action == StepExpect {
+			// Placeholders may not form a valid pattern until they are filled in
+			pattern := placeholder.ReplaceAllString(step.Pattern, "x")
+			if _, err := regexp.Compile(pattern); err != nil {
+				return s, fmt.Errorf("snippet %q: invalid pattern: %w", s.Name, err)
+			}
+		}
+	}
+
+	if s.ID == "" {
+		s.ID = fmt.Sprintf("snip_%d", time.Now().UnixNano())
+	}
+	for i, existing := range sm.snippets {
+		if existing.ID == s.ID {
+			sm.snippets[i] = s
+			return s, sm.Save()
+		}
+	}
+	sm.snippets = append(sm.snippets, s)
+	return s, sm.Save()
+}
+
+// Delete removes a snippet
+func (sm *SnippetManager) Delete(id string) error {
+	for i, s := range sm.snippets {
+		if s.ID == id {
+			sm.snippets = append(sm.snippets[:i], sm.snippets[i+1:]...)
+			return sm.Save()
+		}
+	}
+	return nil
+}
+
+// Get returns a snippet by ID
+func (sm *SnippetManager) Get(id string) (Snippet, bool) {
+	for _, s := range sm.snippets {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return Snippet{}, false
+}
+
+// GetAll returns all snippets
+func (sm *SnippetManager) GetAll() []Snippet {
+	return sm.snippets
+}
+
+// ForGroup returns the snippets offered for sessions in group
+func (sm *SnippetManager) ForGroup(group string) []Snippet {
+	result := []Snippet{}
+	for _, s := range sm.snippets {
+		if s.Group == "" || s.Group == group {
+			result = append(result, s)
+		}
+	}
+	return result
+}
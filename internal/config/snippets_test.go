@@ -0,0 +1,38 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSnippetExpand(t *testing.T) {
+	s := Snippet{
+		Body: "systemctl restart ${service} && echo $${HOME}",
+		Steps: []LoginStep{
+			{Action: StepSend, Text: "sudo -u ${user} -i\r"},
+			{Action: StepExpect, Pattern: `${user}@.*\$ $`},
+		},
+	}
+
+	if got := s.Variables(); !reflect.DeepEqual(got, []string{"service", "user"}) {
+		t.Errorf("Unexpected variables %q", got)
+	}
+
+	out, err := s.Expand(map[string]string{"service": "nginx", "user": "app.svc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Body != "systemctl restart nginx && echo ${HOME}" {
+		t.Errorf("Unexpected body %q", out.Body)
+	}
+	if out.Steps[0].Text != "sudo -u app.svc -i\r" || out.Steps[1].Pattern != `app\.svc@.*\$ $` {
+		t.Errorf("Unexpected steps %+v", out.Steps)
+	}
+	if s.Steps[0].Text != "sudo -u ${user} -i\r" {
+		t.Error("Expand changed the original snippet")
+	}
+
+	if _, err := s.Expand(map[string]string{"service": "nginx"}); err == nil {
+		t.Error("Missing variable was not reported")
+	}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// Trigger actions
+const (
+	TriggerNotify    = "notify"    // show a notification with Text, or the match if empty
+	TriggerHighlight = "highlight" // mark the matched text in the terminal with Color
+	TriggerAlert     = "alert"     // play the alert sound
+	TriggerRespond   = "respond"   // send Text verbatim (include "\r" to press Enter)
+	TriggerSecret    = "secret"    // send the keyring secret named Secret followed by Enter
+)
+
+// Trigger is a rule matched against terminal output
+type Trigger struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Pattern  string `json:"pattern"`
+	Action   string `json:"action"`
+	Text     string `json:"text,omitempty"`
+	Secret   string `json:"secret,omitempty"`
+	Color    string `json:"color,omitempty"`
+	Enabled  bool   `json:"enabled"`
+	Cooldown int    `json:"cooldown,omitempty"` // minimum seconds between firings, 0 uses the default
+}
+
+// Validate checks that a trigger can be used
+func (t Trigger) Validate() error {
+	if t.Pattern == "" {
+		return fmt.Errorf("trigger %q has no pattern", t.Name)
+	}
+	if _, err := regexp.Compile(t.Pattern); err != nil {
+		return fmt.Errorf("trigger %q: invalid pattern: %w", t.Name, err)
+	}
+
+	switch t.Action {
+	case TriggerNotify, TriggerHighlight, TriggerAlert, TriggerRespond:
+	case TriggerSecret:
+		if t.Secret == "" {
+			return fmt.Errorf("trigger %q has no secret", t.Name)
+		}
+	default:
+		return fmt.Errorf("trigger %q: unknown action %q", t.Name, t.Action)
+	}
+	return nil
+}
+
+// TriggerManager handles saving and loading global triggers. Triggers for a
+// single session are stored with the session instead.
+type TriggerManager struct {
+	configPath string
+	triggers   []Trigger
+}
+
+// NewTriggerManager creates a new trigger manager
+func NewTriggerManager() (*TriggerManager, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	configDir := filepath.Join(homeDir, ".genpilot")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return nil, err
+	}
+
+	tm := &TriggerManager{
+		configPath: filepath.Join(configDir, "triggers.json"),
+		triggers:   make([]Trigger, 0),
+	}
+
+	tm.Load()
+	return tm, nil
+}
+
+// Save saves triggers to disk
+func (tm *TriggerManager) Save() error {
+	data, err := json.MarshalIndent(tm.triggers, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(tm.configPath, data, 0600)
+}
+
+// Load loads triggers from disk
+func (tm *TriggerManager) Load() error {
+	data, err := os.ReadFile(tm.configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(data, &tm.triggers)
+}
+
+// Set adds a trigger or replaces the one with the same ID and returns it.
+// New triggers get an ID assigned.
+func (tm *TriggerManager) Set(t Trigger) (Trigger, error) {
+	if err := t.Validate(); err != nil {
+		return t, err
+	}
+	if t.Action == TriggerSecret {
+		// A global rule fires on every host, so any of them could print
+		// the prompt and be sent the secret
+		return t, fmt.Errorf("trigger %q: secrets can only be sent by a session's own triggers", t.Name)
+	}
+
+	if t.ID == "" {
+		t.ID = NewTriggerID()
+	}
+	for i, existing := range tm.triggers {
+		if existing.ID == t.ID {
+			tm.triggers[i] = t
+			return t, tm.Save()
+		}
+	}
+	tm.triggers = append(tm.triggers, t)
+	return t, tm.Save()
+}
+
+// SetEnabled turns a trigger on or off
+func (tm *TriggerManager) SetEnabled(id string, enabled bool) error {
+	for i, t := range tm.triggers {
+		if t.ID == id {
+			tm.triggers[i].Enabled = enabled
+			return tm.Save()
+		}
+	}
+	return fmt.Errorf("trigger %s not found", id)
+}
+
+// Delete removes a trigger
+func (tm *TriggerManager) Delete(id string) error {
+	for i, t := range tm.triggers {
+		if t.ID == id {
+			tm.triggers = append(tm.triggers[:i], tm.triggers[i+1:]...)
+			return tm.Save()
+		}
+	}
+	return nil
+}
+
+// GetAll returns all global triggers. Secret triggers saved before they
+// were restricted to sessions are left out.
+func (tm *TriggerManager) GetAll() []Trigger {
+	triggers := make([]Trigger, 0, len(tm.triggers))
+	for _, t := range tm.triggers {
+		if t.Action != TriggerSecret {
+			triggers = append(triggers, t)
+		}
+	}
+	return triggers
+}
+
+// NewTriggerID returns an ID for a new trigger
+func NewTriggerID() string {
+	return fmt.Sprintf("trg_%d", time.Now().UnixNano())
+}
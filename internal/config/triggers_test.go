@@ -0,0 +1,32 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGlobalTriggersCannotSendSecrets(t *testing.T) {
+	tm := &TriggerManager{configPath: filepath.Join(t.TempDir(), "triggers.json")}
+
+	secret := Trigger{Name: "sudo", Pattern: `\[sudo\] password`, Action: TriggerSecret, Secret: "root-pw", Enabled: true}
+	if err := secret.Validate(); err != nil {
+		t.Fatalf("Valid as a session trigger: %v", err)
+	}
+	if _, err := tm.Set(secret); err == nil {
+		t.Error("Saved a global secret trigger")
+	}
+
+	// One saved before the restriction is not handed out
+	tm.triggers = append(tm.triggers, secret)
+	if _, err := tm.Set(Trigger{Name: "err", Pattern: "error", Action: TriggerNotify}); err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range tm.GetAll() {
+		if got.Action == TriggerSecret {
+			t.Errorf("GetAll returned %+v", got)
+		}
+	}
+	if len(tm.GetAll()) != 1 {
+		t.Errorf("GetAll returned %d triggers, want 1", len(tm.GetAll()))
+	}
+}
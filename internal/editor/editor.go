@@ -0,0 +1,176 @@
+// Package editor opens files in a local editor and watches them for saves.
+// Files are kept in a private workspace that is removed when done.
+package editor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Genpilot/internal/cmdline"
+)
+
+// Workspace is a private temporary directory holding files being edited
+type Workspace struct {
+	dir string
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewWorkspace creates a workspace only the current user can read
+func NewWorkspace() (*Workspace, error) {
+	dir, err := os.MkdirTemp("", "genpilot-edit-")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &Workspace{dir: dir}, nil
+}
+
+// Dir is the workspace's directory
+func (w *Workspace) Dir() string {
+	return w.dir
+}
+
+// File returns a fresh local path for a file called name. Each file gets
+// its own subdirectory so it keeps its name, and with it the editor's
+// syntax highlighting.
+func (w *Workspace) File(name string) (string, error) {
+	w.mu.Lock()
+	w.next++
+	sub := filepath.Join(w.dir, strconv.Itoa(w.next))
+	w.mu.Unlock()
+
+	if err := os.Mkdir(sub, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(sub, filepath.Base(name)), nil
+}
+
+// Remove deletes the workspace and everything in it
+func (w *Workspace) Remove() error {
+	return os.RemoveAll(w.dir)
+}
+
+// Command returns the command that opens path in editor. The path is
+// appended unless an argument contains {file}. An empty editor uses the
+// system's default application for the file.
+func Command(editor, path string) (*exec.Cmd, error) {
+	if strings.TrimSpace(editor) == "" {
+		switch runtime.GOOS {
+		case "windows":
+			return exec.Command("rundll32", "url.dll,FileProtocolHandler", path), nil
+		case "darwin":
+			return exec.Command("open", path), nil
+		default:
+			return exec.Command("xdg-open", path), nil
+		}
+	}
+
+	args, err := cmdline.Split(editor)
+	if err != nil {
+		return nil, err
+	}
+	placed := false
+	for i, arg := range args {
+		if strings.Contains(arg, "{file}") {
+			args[i] = strings.ReplaceAll(arg, "{file}", path)
+			placed = true
+		}
+	}
+	if !placed {
+		args = append(args, path)
+	}
+	return exec.Command(args[0], args[1:]...), nil
+}
+
+// Open starts the editor on path without waiting for it to exit
+func Open(editor, path string) error {
+	cmd, err := Command(editor, path)
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start editor: %w", err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// Watcher polls a file for saves
+type Watcher struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Watch calls saved each time path is written, once its size and
+// modification time have held still for one interval so a save in
+// progress isn't caught halfway. Editors that save by replacing the file
+// are seen too. saved runs on the watcher's goroutine and must not call
+// Stop.
+func Watch(path string, interval time.Duration, saved func()) *Watcher {
+	w := &Watcher{stop: make(chan struct{}), done: make(chan struct{})}
+	last, _ := stat(path)
+
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		pending := false
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+			}
+
+			cur, ok := stat(path)
+			if !ok {
+				// Mid-replace, or deleted; wait for it to come back
+				continue
+			}
+			if cur != last {
+				last = cur
+				pending = true
+				continue
+			}
+			if pending {
+				pending = false
+				saved()
+			}
+		}
+	}()
+	return w
+}
+
+// Stop stops watching and waits for a saved call in progress to finish
+func (w *Watcher) Stop() {
+	w.once.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+// fileState is what a save changes
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+func stat(path string) (fileState, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}, false
+	}
+	return fileState{info.Size(), info.ModTime()}, true
+}
@@ -0,0 +1,87 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCommandPlacesFile(t *testing.T) {
+	cmd, err := Command("code --wait", "/tmp/a b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cmd.Args[1:]; !reflect.DeepEqual(got, []string{"--wait", "/tmp/a b.txt"}) {
+		t.Errorf("Args %q", got)
+	}
+
+	cmd, err = Command("emacsclient --eval (find-file\"{file}\")", "/tmp/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cmd.Args[1:]; !reflect.DeepEqual(got, []string{"--eval", "(find-file/tmp/x)"}) {
+		t.Errorf("Args %q", got)
+	}
+}
+
+func TestWorkspace(t *testing.T) {
+	ws, err := NewWorkspace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := ws.File("/etc/nginx/nginx.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ws.File("nginx.conf")
+	if a == b || filepath.Base(a) != "nginx.conf" || filepath.Base(b) != "nginx.conf" {
+		t.Errorf("Files %q and %q", a, b)
+	}
+	if info, err := os.Stat(ws.Dir()); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("Workspace mode %v, %v", info.Mode(), err)
+	}
+
+	if err := ws.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(ws.Dir()); !os.IsNotExist(err) {
+		t.Errorf("Workspace left behind: %v", err)
+	}
+}
+
+func TestWatchReportsSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "f.txt")
+	if err := os.WriteFile(path, []byte("one"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	saves := make(chan struct{}, 10)
+	w := Watch(path, 10*time.Millisecond, func() { saves <- struct{}{} })
+	defer w.Stop()
+
+	select {
+	case <-saves:
+		t.Fatal("Saved before any write")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A save that replaces the file, as many editors do
+	tmp := path + ".tmp"
+	os.WriteFile(tmp, []byte("two, longer"), 0600)
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-saves:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Save not seen")
+	}
+
+	select {
+	case <-saves:
+		t.Fatal("One save reported twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,112 @@
+// Package fileinfo describes files for listings: Unix permission bits,
+// owner and group names and whether a file is hidden.
+package fileinfo
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Perm returns a mode's Unix permission bits, setuid, setgid and sticky
+// included
+func Perm(mode os.FileMode) uint32 {
+	perm := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		perm |= 0o4000
+	}
+	if mode&os.ModeSetgid != 0 {
+		perm |= 0o2000
+	}
+	if mode&os.ModeSticky != 0 {
+		perm |= 0o1000
+	}
+	return perm
+}
+
+// Hidden reports whether a file is hidden: a dot file, or on Windows one
+// with the hidden attribute
+func Hidden(info os.FileInfo) bool {
+	return strings.HasPrefix(info.Name(), ".") || sysHidden(info)
+}
+
+// Owner returns a local file's owner and group ids, if the platform has them
+func Owner(info os.FileInfo) (uid, gid uint32, ok bool) {
+	return sysOwner(info)
+}
+
+// ParseIDs reads /etc/passwd or /etc/group content into a map of id to
+// name. The first name listed for an id wins.
+func ParseIDs(data []byte) map[uint32]string {
+	names := make(map[uint32]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// name:password:id:...
+		fields := strings.SplitN(line, ":", 4)
+		if len(fields) < 3 || fields[0] == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			continue
+		}
+		if _, seen := names[uint32(id)]; !seen {
+			names[uint32(id)] = fields[0]
+		}
+	}
+	return names
+}
+
+var (
+	localMu     sync.Mutex
+	localUsers  = make(map[uint32]string)
+	localGroups = make(map[uint32]string)
+)
+
+// UserName returns the local user name for uid, or "" if there is none.
+// Lookups are cached.
+func UserName(uid uint32) string {
+	return lookupCached(localUsers, uid, func(id string) (string, error) {
+		u, err := user.LookupId(id)
+		if err != nil {
+			return "", err
+		}
+		return u.Username, nil
+	})
+}
+
+// GroupName returns the local group name for gid, or "" if there is none.
+// Lookups are cached.
+func GroupName(gid uint32) string {
+	return lookupCached(localGroups, gid, func(id string) (string, error) {
+		g, err := user.LookupGroupId(id)
+		if err != nil {
+			return "", err
+		}
+		return g.Name, nil
+	})
+}
+
+func lookupCached(cache map[uint32]string, id uint32, lookup func(string) (string, error)) string {
+	localMu.Lock()
+	name, ok := cache[id]
+	localMu.Unlock()
+	if ok {
+		return name
+	}
+
+	// Unknown ids are cached as "" so they aren't looked up again
+	name, _ = lookup(strconv.FormatUint(uint64(id), 10))
+	localMu.Lock()
+	cache[id] = name
+	localMu.Unlock()
+	return name
+}
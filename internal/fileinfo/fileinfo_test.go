@@ -0,0 +1,63 @@
+package fileinfo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseIDs(t *testing.T) {
+	passwd := "# comment\n" +
+		"root:x:0:0:root:/root:/bin/bash\n" +
+		"bob:x:1000:1000:Bob:/home/bob:/bin/sh\n" +
+		"toor:x:0:0:alias:/root:/bin/sh\n" +
+		"broken line\n" +
+		"nobody:x:notanumber:0::/:\n"
+	got := ParseIDs([]byte(passwd))
+	if len(got) != 2 || got[0] != "root" || got[1000] != "bob" {
+		t.Errorf("Parsed %v", got)
+	}
+
+	group := "wheel:x:10:bob,alice\nstaff:*:20:\n"
+	got = ParseIDs([]byte(group))
+	if len(got) != 2 || got[10] != "wheel" || got[20] != "staff" {
+		t.Errorf("Parsed %v", got)
+	}
+}
+
+func TestPerm(t *testing.T) {
+	tests := []struct {
+		mode os.FileMode
+		want uint32
+	}{
+		{0o644, 0o644},
+		{os.ModeDir | 0o755, 0o755},
+		{os.ModeSetuid | 0o755, 0o4755},
+		{os.ModeDir | os.ModeSticky | 0o777, 0o1777},
+		{os.ModeSetgid | 0o750, 0o2750},
+	}
+	for _, tt := range tests {
+		if got := Perm(tt.mode); got != tt.want {
+			t.Errorf("Perm(%v) = %o, want %o", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestHiddenAndOwner(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{".profile", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hidden, _ := os.Stat(filepath.Join(dir, ".profile"))
+	shown, _ := os.Stat(filepath.Join(dir, "notes.txt"))
+	if !Hidden(hidden) || Hidden(shown) {
+		t.Errorf("Hidden: .profile %v, notes.txt %v", Hidden(hidden), Hidden(shown))
+	}
+
+	if uid, _, ok := Owner(shown); ok && int(uid) != os.Getuid() {
+		t.Errorf("Owner uid %d, want %d", uid, os.Getuid())
+	}
+}
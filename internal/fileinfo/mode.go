@@ -0,0 +1,154 @@
+package fileinfo
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ModeSpec is a permission change as chmod takes it: octal like 755 or
+// symbolic like u+x,g-w,o=r
+type ModeSpec struct {
+	octal   bool
+	perm    uint32
+	clauses []modeClause
+}
+
+// modeClause is one symbolic operation, e.g. the g-w in u+x,g-w
+type modeClause struct {
+	who   uint32 // bits the clause may touch
+	op    byte   // +, - or =
+	perms string // any of rwxXst
+}
+
+// Bits each class of user owns, special bits included
+const (
+	whoUser  = 0o4700
+	whoGroup = 0o2070
+	whoOther = 0o1007
+	whoAll   = whoUser | whoGroup | whoOther
+)
+
+// ParseMode parses an octal or symbolic mode. Symbolic clauses without a
+// class apply to everyone; there is no umask on the remote side to honour.
+func ParseMode(spec string) (ModeSpec, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return ModeSpec{}, fmt.Errorf("empty mode")
+	}
+
+	if spec[0] >= '0' && spec[0] <= '9' {
+		perm, err := strconv.ParseUint(spec, 8, 32)
+		if err != nil || len(spec) > 4 {
+			return ModeSpec{}, fmt.Errorf("invalid mode %q", spec)
+		}
+		return ModeSpec{octal: true, perm: uint32(perm)}, nil
+	}
+
+	var m ModeSpec
+	for _, part := range strings.Split(spec, ",") {
+		clauses, err := parseClauses(part)
+		if err != nil {
+			return ModeSpec{}, fmt.Errorf("invalid mode %q: %w", spec, err)
+		}
+		m.clauses = append(m.clauses, clauses...)
+	}
+	return m, nil
+}
+
+// parseClauses parses one comma-separated part, which may chain
+// operations like u+x-w
+func parseClauses(part string) ([]modeClause, error) {
+	var who uint32
+	i := 0
+	for ; i < len(part) && strings.IndexByte("ugoa", part[i]) >= 0; i++ {
+		switch part[i] {
+		case 'u':
+			who |= whoUser
+		case 'g':
+			who |= whoGroup
+		case 'o':
+			who |= whoOther
+		case 'a':
+			who |= whoAll
+		}
+	}
+	if who == 0 {
+		who = whoAll
+	}
+	if i == len(part) {
+		return nil, fmt.Errorf("missing +, - or = in %q", part)
+	}
+
+	var clauses []modeClause
+	for i < len(part) {
+		op := part[i]
+		if op != '+' && op != '-' && op != '=' {
+			return nil, fmt.Errorf("unexpected %q in %q", op, part)
+		}
+		i++
+		start := i
+		for i < len(part) && strings.IndexByte("rwxXst", part[i]) >= 0 {
+			i++
+		}
+		clauses = append(clauses, modeClause{who: who, op: op, perms: part[start:i]})
+	}
+	return clauses, nil
+}
+
+// Apply returns the permission bits a file with perm ends up with
+func (m ModeSpec) Apply(perm uint32, isDir bool) uint32 {
+	if m.octal {
+		return m.perm
+	}
+	for _, c := range m.clauses {
+		var bits uint32
+		for _, p := range c.perms {
+			switch p {
+			case 'r':
+				bits |= 0o444
+			case 'w':
+				bits |= 0o222
+			case 'x':
+				bits |= 0o111
+			case 'X':
+				// Execute only for directories and files already executable
+				if isDir || perm&0o111 != 0 {
+					bits |= 0o111
+				}
+			case 's':
+				bits |= 0o6000
+			case 't':
+				bits |= 0o1000
+			}
+		}
+		bits &= c.who
+
+		switch c.op {
+		case '+':
+			perm |= bits
+		case '-':
+			perm &^= bits
+		case '=':
+			perm = perm&^c.who | bits
+		}
+	}
+	return perm
+}
+
+// FileMode converts Unix permission bits back to an os.FileMode, the
+// inverse of Perm
+func FileMode(perm uint32) os.FileMode {
+	mode := os.FileMode(perm & 0o777)
+	if perm&0o4000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if perm&0o2000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if perm&0o1000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode
+}
@@ -0,0 +1,63 @@
+package fileinfo
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		spec  string
+		perm  uint32
+		isDir bool
+		want  uint32
+	}{
+		{"755", 0o600, false, 0o755},
+		{"0640", 0o777, false, 0o640},
+		{"4755", 0o644, false, 0o4755},
+		{"u+x", 0o644, false, 0o744},
+		{"u+x,g-w", 0o664, false, 0o744},
+		{"go-rwx", 0o755, false, 0o700},
+		{"+x", 0o644, false, 0o755},
+		{"a=r", 0o755, false, 0o444},
+		{"o=", 0o757, false, 0o750},
+		{"u+x-w", 0o644, false, 0o544},
+		{"g=rw,o-r", 0o704, false, 0o760},
+		{"a+X", 0o644, false, 0o644},
+		{"a+X", 0o644, true, 0o755},
+		{"a+X", 0o744, false, 0o755},
+		{"u+s", 0o755, false, 0o4755},
+		{"g+s", 0o755, true, 0o2755},
+		{"+t", 0o777, true, 0o1777},
+		{"o-t", 0o1777, true, 0o777},
+	}
+	for _, tt := range tests {
+		m, err := ParseMode(tt.spec)
+		if err != nil {
+			t.Errorf("ParseMode(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := m.Apply(tt.perm, tt.isDir); got != tt.want {
+			t.Errorf("%q on %o (dir %v) = %o, want %o", tt.spec, tt.perm, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestParseModeErrors(t *testing.T) {
+	for _, spec := range []string{"", "9", "12345", "u", "u+q", "x+r", "u+x,", "g=u"} {
+		if _, err := ParseMode(spec); err == nil {
+			t.Errorf("ParseMode(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestFileModeRoundTrip(t *testing.T) {
+	for _, perm := range []uint32{0, 0o644, 0o755, 0o4755, 0o2750, 0o1777, 0o7777} {
+		if got := Perm(FileMode(perm)); got != perm {
+			t.Errorf("Perm(FileMode(%o)) = %o", perm, got)
+		}
+	}
+	if FileMode(0o4644)&os.ModeSetuid == 0 {
+		t.Error("setuid lost")
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix && !windows
+
+package fileinfo
+
+import "os"
+
+func sysOwner(os.FileInfo) (uint32, uint32, bool) {
+	return 0, 0, false
+}
+
+func sysHidden(os.FileInfo) bool {
+	return false
+}
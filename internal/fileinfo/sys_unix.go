@@ -0,0 +1,20 @@
+//go:build unix
+
+package fileinfo
+
+import (
+	"os"
+	"syscall"
+)
+
+func sysOwner(info os.FileInfo) (uint32, uint32, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return st.Uid, st.Gid, true
+}
+
+func sysHidden(os.FileInfo) bool {
+	return false
+}
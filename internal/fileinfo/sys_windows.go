@@ -0,0 +1,15 @@
+package fileinfo
+
+import (
+	"os"
+	"syscall"
+)
+
+func sysOwner(os.FileInfo) (uint32, uint32, bool) {
+	return 0, 0, false
+}
+
+func sysHidden(info os.FileInfo) bool {
+	attrs, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	return ok && attrs.FileAttributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+}
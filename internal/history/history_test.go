@@ -0,0 +1,61 @@
+package history
+
+import (
+	"testing"
+)
+
+func TestTrackerMarkers(t *testing.T) {
+	var got []Entry
+	tr := NewTracker(func(e Entry) { got = append(got, e) })
+
+	// Typed input is ignored once the shell reports markers
+	tr.Output([]byte("\x1b]133;A\x07$ \x1b]133;B\x07"))
+	tr.Input([]byte("make tesx\x7ft\r"))
+	tr.Output([]byte("make \x1b[1mtest\x1b[0m\r\n\x1b]133;C\x07FAIL\r\n\x1b]133;D;2\x07"))
+	tr.Output([]byte("\x1b]133;A\x07$ \x1b]133;B\x07ls\r\n\x1b]133;C\x07"))
+	tr.Output([]byte("\x1b]133;A\x07"))
+
+	if len(got) != 2 || got[0].Command != "make test" || got[0].ExitCode == nil || *got[0].ExitCode != 2 {
+		t.Fatalf("Unexpected entries %+v", got)
+	}
+	if got[1].Command != "ls" || got[1].ExitCode != nil {
+		t.Errorf("Unexpected entry without exit code %+v", got[1])
+	}
+}
+
+func TestTrackerInput(t *testing.T) {
+	var got []string
+	tr := NewTracker(func(e Entry) { got = append(got, e.Command) })
+
+	tr.Output([]byte("user@host:~$ "))
+	tr.Input([]byte("cd /tmpx\x7f\r"))
+	tr.Input([]byte("git st\t\r"))           // completed by the shell, text unknown
+	tr.Input([]byte("\x1b[A\r"))             // recalled from shell history
+	tr.Input([]byte("rm -rf build\x15ls\r")) // line killed and retyped
+	tr.Output([]byte("\r\n[sudo] password for user: "))
+	tr.Input([]byte("hunter2\r"))
+
+	if len(got) != 2 || got[0] != "cd /tmp" || got[1] != "ls" {
+		t.Errorf("Unexpected commands %q", got)
+	}
+}
+
+func TestStoreSearch(t *testing.T) {
+	s, err := NewStoreAt(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range []string{"git status", "ls -la", "git pull", "git status"} {
+		if err := s.Add("example.com:22", Entry{Command: cmd}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := s.Search("example.com:22", "GIT", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Command != "git status" || entries[1].Command != "git pull" {
+		t.Errorf("Unexpected search result %+v", entries)
+	}
+}
@@ -0,0 +1,164 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxEntries is how many commands are kept per host
+const MaxEntries = 10000
+
+// Entry is one command run on a host
+type Entry struct {
+	Command  string    `json:"command"`
+	Time     time.Time `json:"time"`
+	ExitCode *int      `json:"exit_code,omitempty"` // nil when the shell didn't report it
+	Dir      string    `json:"dir,omitempty"`
+	Session  string    `json:"session,omitempty"`
+}
+
+// Store keeps command history in one JSON lines file per host
+type Store struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewStore creates a store in ~/.genpilot/history
+func NewStore() (*Store, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreAt(filepath.Join(homeDir, ".genpilot", "history"))
+}
+
+// NewStoreAt creates a store in dir
+func NewStoreAt(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+func (s *Store) path(host string) string {
+	return filepath.Join(s.dir, unsafeChars.ReplaceAllString(host, "_")+".jsonl")
+}
+
+// Add appends a command to a host's history
+func (s *Store) Add(host string, e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path(host), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	// Trim once the file is well over the limit so it isn't rewritten on every add
+	if info, err := os.Stat(s.path(host)); err == nil && info.Size() > MaxEntries*256 {
+		return s.trim(host)
+	}
+	return nil
+}
+
+func (s *Store) trim(host string) error {
+	entries, err := s.load(host)
+	if err != nil || len(entries) <= MaxEntries {
+		return err
+	}
+	entries = entries[len(entries)-MaxEntries:]
+
+	var buf strings.Builder
+	for _, e := range entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return os.WriteFile(s.path(host), []byte(buf.String()), 0600)
+}
+
+// load reads a host's history, oldest first. Corrupt lines are skipped.
+func (s *Store) load(host string) ([]Entry, error) {
+	f, err := os.Open(s.path(host))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil && e.Command != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// Search returns a host's commands containing query, newest first. Repeats
+// of a command only show up once, as their latest run. An empty query
+// matches everything; limit <= 0 means no limit.
+func (s *Store) Search(host, query string, limit int) ([]Entry, error) {
+	s.mu.Lock()
+	entries, err := s.load(host)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(query)
+	seen := make(map[string]bool)
+	result := []Entry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if seen[e.Command] || !strings.Contains(strings.ToLower(e.Command), query) {
+			continue
+		}
+		seen[e.Command] = true
+		result = append(result, e)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Clear deletes a host's history
+func (s *Store) Clear(host string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(host))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,204 @@
+package history
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"Genpilot/internal/terminal"
+)
+
+// Longest visible output line kept to recognise password prompts
+const maxPromptLine = 256
+
+var passwordPrompt = regexp.MustCompile(`(?i)(password|passphrase|pin|token)[^:]*:\s*$`)
+
+// Input escape states
+const (
+	inputGround = iota
+	inputEscape
+	inputCSI
+)
+
+// Tracker notices commands submitted at a shell prompt. Shells with
+// integration markers (OSC 133) report where the command line starts and
+// ends and its exit code. Without them typed input is followed line by
+// line; lines edited with arrow keys or completion can't be followed and
+// are left out, as is anything typed at a password prompt.
+type Tracker struct {
+	mu        sync.Mutex
+	onCommand func(Entry)
+	now       func() time.Time
+
+	osc       terminal.OSCScanner
+	marked    bool // the shell emits OSC 133
+	capturing bool // between the command start and executed markers
+	cmd       []byte
+	pending   *Entry // run but not finished
+
+	stripper terminal.Stripper
+	lastLine []byte // visible text of the current output line
+
+	input      []byte
+	inputDirty bool
+	inputState int
+}
+
+// NewTracker creates a tracker that passes each command to onCommand
+func NewTracker(onCommand func(Entry)) *Tracker {
+	return &Tracker{onCommand: onCommand, now: time.Now}
+}
+
+// Output feeds shell output to the tracker
+func (t *Tracker) Output(p []byte) {
+	t.mu.Lock()
+	var done []Entry
+	for _, b := range p {
+		if t.capturing {
+			t.cmd = append(t.cmd, b)
+		}
+		if o, ok := t.osc.Feed(b); ok && o.Code == "133" {
+			done = t.mark(o.Data, done)
+		}
+	}
+
+	for _, c := range t.stripper.Strip(p) {
+		switch c {
+		case '\n':
+			t.lastLine = t.lastLine[:0]
+		case '\r':
+		default:
+			t.lastLine = append(t.lastLine, c)
+		}
+	}
+	if len(t.lastLine) > maxPromptLine {
+		t.lastLine = append(t.lastLine[:0], t.lastLine[len(t.lastLine)-maxPromptLine:]...)
+	}
+	t.mu.Unlock()
+
+	for _, e := range done {
+		t.onCommand(e)
+	}
+}
+
+// mark handles an OSC 133 shell integration marker
+func (t *Tracker) mark(data string, done []Entry) []Entry {
+	t.marked = true
+	kind, args, _ := strings.Cut(data, ";")
+
+	switch kind {
+	case "A": // prompt starts; a command that never reported finishing is done
+		t.capturing = false
+		if t.pending != nil {
+			done = append(done, *t.pending)
+			t.pending = nil
+		}
+
+	case "B": // command line starts
+		t.capturing = true
+		t.cmd = t.cmd[:0]
+
+	case "C": // command line submitted
+		if !t.capturing {
+			break
+		}
+		t.capturing = false
+		if command := cleanCommand(t.cmd); command != "" {
+			t.pending = &Entry{Command: command, Time: t.now()}
+		}
+
+	case "D": // command finished, with its exit code if the shell knows it
+		if t.pending == nil {
+			break
+		}
+		if code, err := strconv.Atoi(strings.SplitN(args, ";", 2)[0]); err == nil {
+			t.pending.ExitCode = &code
+		}
+		done = append(done, *t.pending)
+		t.pending = nil
+	}
+	return done
+}
+
+// cleanCommand turns the echoed command line into the command text
+func cleanCommand(echo []byte) string {
+	text := terminal.StripANSI(string(echo))
+	var out []rune
+	for _, r := range text {
+		switch r {
+		case '\b':
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+		case '\r', '\n':
+		default:
+			out = append(out, r)
+		}
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// Input feeds typed input to the tracker. It is ignored once the shell has
+// shown it emits integration markers.
+func (t *Tracker) Input(p []byte) {
+	t.mu.Lock()
+	if t.marked {
+		t.mu.Unlock()
+		return
+	}
+
+	var done []Entry
+	for _, b := range p {
+		switch t.inputState {
+		case inputEscape:
+			t.inputState = inputGround
+			if b == '[' || b == 'O' {
+				t.inputState = inputCSI
+			}
+			continue
+		case inputCSI:
+			if b >= 0x40 && b <= 0x7e {
+				t.inputState = inputGround
+			}
+			continue
+		}
+
+		switch {
+		case b == 0x1b:
+			// Cursor keys and history recall change the line in ways we can't see
+			t.inputState = inputEscape
+			t.inputDirty = true
+		case b == '\r' || b == '\n':
+			command := strings.TrimSpace(string(t.input))
+			if command != "" && !t.inputDirty && !passwordPrompt.Match(t.lastLine) {
+				done = append(done, Entry{Command: command, Time: t.now()})
+			}
+			t.input = t.input[:0]
+			t.inputDirty = false
+		case b == 0x7f || b == 0x08:
+			if len(t.input) > 0 {
+				_, size := utf8.DecodeLastRune(t.input)
+				t.input = t.input[:len(t.input)-size]
+			}
+		case b == 0x03 || b == 0x15: // Ctrl-C, Ctrl-U
+			t.input = t.input[:0]
+			t.inputDirty = false
+		case b == 0x17: // Ctrl-W
+			trimmed := strings.TrimRight(string(t.input), " ")
+			t.input = t.input[:strings.LastIndex(trimmed, " ")+1]
+		case b < 0x20:
+			// Completion and other editing keys
+			t.inputDirty = true
+		default:
+			t.input = append(t.input, b)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, e := range done {
+		t.onCommand(e)
+	}
+}
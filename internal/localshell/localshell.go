@@ -0,0 +1,63 @@
+// Package localshell runs a shell on this machine behind a pseudo-terminal
+package localshell
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"Genpilot/internal/cmdline"
+)
+
+// ErrUnsupported is returned by Start where pseudo-terminals aren't available
+var ErrUnsupported = errors.New("local shells are not supported on this platform")
+
+// Options configure a local shell
+type Options struct {
+	Command string   // program and arguments; the user's shell if empty
+	Dir     string   // working directory, "~" for home; home if empty
+	Env     []string // KEY=VALUE pairs added to the app's environment
+	Rows    int
+	Cols    int
+}
+
+// ExitStatus says how a shell ended
+type ExitStatus struct {
+	Code   int    // -1 when killed by a signal
+	Signal string // set when killed by a signal
+}
+
+// argv returns the program and arguments to run. Double quotes in Command
+// group words as they do for the editor command.
+func (o Options) argv() ([]string, error) {
+	if strings.TrimSpace(o.Command) != "" {
+		return cmdline.Split(o.Command)
+	}
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return []string{shell}, nil
+	}
+	return []string{"/bin/sh"}, nil
+}
+
+// dir resolves the working directory, expanding a leading "~"
+func (o Options) dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case o.Dir == "" || o.Dir == "~":
+		return home, nil
+	case strings.HasPrefix(o.Dir, "~/"):
+		return filepath.Join(home, o.Dir[2:]), nil
+	}
+	return o.Dir, nil
+}
+
+// env returns the environment of the shell: the app's own, with TERM set
+// for xterm and the configured variables on top
+func (o Options) env() []string {
+	env := append(os.Environ(), "TERM=xterm-256color", "COLORTERM=truecolor")
+	return append(env, o.Env...)
+}
@@ -0,0 +1,100 @@
+//go:build linux || darwin
+
+package localshell
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShellRunsInPTY(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Start(Options{
+		Command: "/bin/sh",
+		Dir:     dir,
+		Env:     []string{"GENPILOT_TEST=hello"},
+		Rows:    30,
+		Cols:    100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	var out bytes.Buffer
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(&out, s)
+		close(copied)
+	}()
+
+	s.Write([]byte("echo \"$GENPILOT_TEST $(pwd) $(stty size)\"; exit 3\n"))
+
+	done := make(chan ExitStatus)
+	go func() {
+		status, _ := s.Wait()
+		done <- status
+	}()
+	select {
+	case status := <-done:
+		if status.Code != 3 {
+			t.Errorf("Exit status %+v, want code 3", status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shell didn't exit")
+	}
+	select {
+	case <-copied:
+	case <-time.After(time.Second):
+		s.Close()
+		<-copied
+	}
+
+	if want := "hello " + dir + " 30 100"; !strings.Contains(out.String(), want) {
+		t.Errorf("Output %q doesn't contain %q", out.String(), want)
+	}
+}
+
+func TestShellCloseTwice(t *testing.T) {
+	s, err := Start(Options{Command: "/bin/sh", Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go io.Copy(io.Discard, s)
+
+	// Closing as both process and input must hang up once and succeed twice
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Second Close: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shell didn't exit after Close")
+	}
+}
+
+func TestArgvKeepsQuotedWords(t *testing.T) {
+	got, err := Options{Command: `/bin/sh -c "echo hi there"`}.argv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/bin/sh", "-c", "echo hi there"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("argv = %q, want %q", got, want)
+	}
+
+	if _, err := (Options{Command: `vim "unterminated`}).argv(); err == nil {
+		t.Error("unterminated quote accepted")
+	}
+}
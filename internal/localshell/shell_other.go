@@ -0,0 +1,36 @@
+//go:build !linux && !darwin
+
+package localshell
+
+import "io"
+
+// Shell is a running local shell; this platform has none
+type Shell struct{}
+
+// Start always fails on this platform
+func Start(opts Options) (*Shell, error) {
+	return nil, ErrUnsupported
+}
+
+func (s *Shell) Read(p []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (s *Shell) Write(p []byte) (int, error) {
+	return 0, ErrUnsupported
+}
+
+// WindowChange resizes the terminal
+func (s *Shell) WindowChange(rows, cols int) error {
+	return ErrUnsupported
+}
+
+// Close hangs up on the shell
+func (s *Shell) Close() error {
+	return nil
+}
+
+// Wait waits for the shell to exit and reports how it ended
+func (s *Shell) Wait() (ExitStatus, error) {
+	return ExitStatus{Code: -1}, ErrUnsupported
+}
@@ -0,0 +1,90 @@
+//go:build linux || darwin
+
+package localshell
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"github.com/creack/pty"
+)
+
+// Shell is a running local shell. Reads return its output and writes are
+// its input, both through the pseudo-terminal.
+type Shell struct {
+	cmd *exec.Cmd
+	pty *os.File
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// Start runs a shell in a new pseudo-terminal
+func Start(opts Options) (*Shell, error) {
+	dir, err := opts.dir()
+	if err != nil {
+		return nil, err
+	}
+
+	argv, err := opts.argv()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Env = opts.env()
+
+	f, err := pty.StartWithSize(cmd, &pty.Winsize{Rows: uint16(opts.Rows), Cols: uint16(opts.Cols)})
+	if err != nil {
+		return nil, err
+	}
+	return &Shell{cmd: cmd, pty: f}, nil
+}
+
+func (s *Shell) Read(p []byte) (int, error) {
+	n, err := s.pty.Read(p)
+	if errors.Is(err, syscall.EIO) {
+		// Linux reports the other end closing as EIO
+		err = os.ErrClosed
+	}
+	return n, err
+}
+
+func (s *Shell) Write(p []byte) (int, error) {
+	return s.pty.Write(p)
+}
+
+// WindowChange resizes the terminal
+func (s *Shell) WindowChange(rows, cols int) error {
+	return pty.Setsize(s.pty, &pty.Winsize{Rows: uint16(rows), Cols: uint16(cols)})
+}
+
+// Close hangs up on the shell and everything it started in the terminal.
+// Only the first call does so; a Shell is often both a session's process
+// and its input, and closed as each.
+func (s *Shell) Close() error {
+	s.closeOnce.Do(func() {
+		if p := s.cmd.Process; p != nil {
+			// The shell leads its own session, so this reaches its jobs too
+			syscall.Kill(-p.Pid, syscall.SIGHUP)
+		}
+		s.closeErr = s.pty.Close()
+	})
+	return s.closeErr
+}
+
+// Wait waits for the shell to exit and reports how it ended
+func (s *Shell) Wait() (ExitStatus, error) {
+	err := s.cmd.Wait()
+	state := s.cmd.ProcessState
+	if state == nil {
+		return ExitStatus{Code: -1}, err
+	}
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return ExitStatus{Code: -1, Signal: ws.Signal().String()}, nil
+	}
+	return ExitStatus{Code: state.ExitCode()}, nil
+}
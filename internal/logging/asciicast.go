@@ -0,0 +1,245 @@
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"Genpilot/internal/terminal"
+)
+
+// asciicast v2 event types
+const (
+	CastOutput = "o"
+	CastInput  = "i"
+	CastResize = "r"
+)
+
+// CastHeader is the first line of an asciicast v2 recording
+type CastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// CastEvent is one timed entry of a recording
+type CastEvent struct {
+	Time float64 // seconds since the recording started
+	Type string
+	Data string // resize events carry "COLSxROWS"
+}
+
+// MarshalJSON encodes the event as asciicast's [time, type, data] array
+func (e CastEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.Time, e.Type, e.Data})
+}
+
+// UnmarshalJSON decodes an asciicast [time, type, data] array
+func (e *CastEvent) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return fmt.Errorf("event has %d fields, want 3", len(raw))
+	}
+	if err := json.Unmarshal(raw[0], &e.Time); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &e.Type); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw[2], &e.Data)
+}
+
+// Recorder writes terminal traffic with timing in asciinema's asciicast v2 format
+type Recorder struct {
+	mu          sync.Mutex
+	file        *os.File
+	filePath    string
+	start       time.Time
+	recordInput bool
+	outTail     []byte // incomplete UTF-8 sequence from the last output chunk
+	inTail      []byte
+}
+
+// NewRecorder creates a new recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Start begins recording to a new file
+func (r *Recorder) Start(filePath string, width, height int, title string, recordInput bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file != nil {
+		r.file.Close()
+	}
+
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	r.start = time.Now()
+	header, err := json.Marshal(CastHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: r.start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": "xterm"},
+	})
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(append(header, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	r.file = f
+	r.filePath = filePath
+	r.recordInput = recordInput
+	r.outTail = nil
+	r.inTail = nil
+	return nil
+}
+
+// Stop finishes the recording
+func (r *Recorder) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
+
+func (r *Recorder) event(typ, data string) {
+	line, err := json.Marshal(CastEvent{
+		Time: time.Since(r.start).Seconds(),
+		Type: typ,
+		Data: data,
+	})
+	if err == nil {
+		r.file.Write(append(line, '\n'))
+	}
+}
+
+// WriteOutput records terminal output
+func (r *Recorder) WriteOutput(p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return
+	}
+
+	// JSON strings must be valid UTF-8, so hold back split characters
+	var complete []byte
+	complete, r.outTail = terminal.SplitIncompleteUTF8(append(r.outTail, p...))
+	if len(complete) > 0 {
+		r.event(CastOutput, string(complete))
+	}
+	r.outTail = append([]byte(nil), r.outTail...)
+}
+
+// WriteInput records user input when input recording is enabled
+func (r *Recorder) WriteInput(p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil || !r.recordInput {
+		return
+	}
+
+	var complete []byte
+	complete, r.inTail = terminal.SplitIncompleteUTF8(append(r.inTail, p...))
+	if len(complete) > 0 {
+		r.event(CastInput, string(complete))
+	}
+	r.inTail = append([]byte(nil), r.inTail...)
+}
+
+// Resize records a terminal size change
+func (r *Recorder) Resize(cols, rows int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return
+	}
+	r.event(CastResize, fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// IsRecording returns whether a recording is in progress
+func (r *Recorder) IsRecording() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file != nil
+}
+
+// GetFilePath returns the path of the current or last recording
+func (r *Recorder) GetFilePath() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.filePath
+}
+
+// ReadCast loads an asciicast v2 recording
+func ReadCast(path string) (CastHeader, []CastEvent, error) {
+	var header CastHeader
+
+	f, err := os.Open(path)
+	if err != nil {
+		return header, nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return header, nil, err
+		}
+		return header, nil, fmt.Errorf("empty recording")
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return header, nil, fmt.Errorf("invalid header: %w", err)
+	}
+	if header.Version != 2 {
+		return header, nil, fmt.Errorf("unsupported asciicast version %d", header.Version)
+	}
+
+	var events []CastEvent
+	for line := 2; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var e CastEvent
+		if err := json.Unmarshal([]byte(text), &e); err != nil {
+			return header, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	return header, events, scanner.Err()
+}
+
+// ParseResize splits a resize event's "COLSxROWS" data
+func ParseResize(data string) (cols, rows int, err error) {
+	_, err = fmt.Sscanf(data, "%dx%d", &cols, &rows)
+	return cols, rows, err
+}
@@ -0,0 +1,97 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRecorderRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.cast")
+	r := NewRecorder()
+	if err := r.Start(path, 80, 24, "demo", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Feed output a byte at a time so every multi-byte character is split
+	want := "héllo wörld ✓ 🙂\r\n"
+	for i := 0; i < len(want); i++ {
+		r.WriteOutput([]byte{want[i]})
+	}
+	r.WriteInput([]byte("ignored"))
+	r.Resize(100, 30)
+	r.Stop()
+
+	if r.IsRecording() || r.GetFilePath() != path {
+		t.Errorf("After Stop: recording %v, path %q", r.IsRecording(), r.GetFilePath())
+	}
+
+	header, events, err := ReadCast(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != 2 || header.Width != 80 || header.Height != 24 || header.Title != "demo" {
+		t.Errorf("Header %+v", header)
+	}
+
+	var out strings.Builder
+	var resizes []string
+	for _, e := range events {
+		switch e.Type {
+		case CastOutput:
+			out.WriteString(e.Data)
+		case CastInput:
+			t.Errorf("Input recorded without recordInput: %q", e.Data)
+		case CastResize:
+			resizes = append(resizes, e.Data)
+		}
+	}
+	if out.String() != want {
+		t.Errorf("Output %q, want %q", out.String(), want)
+	}
+	if len(resizes) != 1 || resizes[0] != "100x30" {
+		t.Errorf("Resizes %q", resizes)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("Recording mode %o, want 600", perm)
+		}
+	}
+}
+
+func TestReadCastErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"empty":     "",
+		"version":   `{"version":1,"width":80,"height":24}` + "\n",
+		"header":    "not json\n",
+		"event":     `{"version":2,"width":80,"height":24}` + "\n" + `[0.1, "o"]` + "\n",
+		"malformed": `{"version":2,"width":80,"height":24}` + "\n" + `[0.1, "o", "x"` + "\n",
+	}
+	for name, content := range tests {
+		path := filepath.Join(dir, name+".cast")
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := ReadCast(path); err == nil {
+			t.Errorf("%s: ReadCast succeeded", name)
+		}
+	}
+}
+
+func TestParseResize(t *testing.T) {
+	cols, rows, err := ParseResize("132x43")
+	if err != nil || cols != 132 || rows != 43 {
+		t.Errorf("ParseResize = %d, %d, %v", cols, rows, err)
+	}
+	if _, _, err := ParseResize("wide"); err == nil {
+		t.Error("ParseResize(wide) succeeded")
+	}
+}
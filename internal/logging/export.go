@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"Genpilot/internal/terminal"
+)
+
+// Export formats
+const (
+	ExportText = "text"
+	ExportHTML = "html"
+)
+
+// RenderCast replays a recording's output through a terminal emulator
+func RenderCast(path string) (*terminal.Screen, CastHeader, error) {
+	header, events, err := ReadCast(path)
+	if err != nil {
+		return nil, header, err
+	}
+
+	screen := terminal.NewScreen(header.Width, header.Height)
+	for _, e := range events {
+		switch e.Type {
+		case CastOutput:
+			screen.Write([]byte(e.Data))
+		case CastResize:
+			if cols, rows, err := ParseResize(e.Data); err == nil {
+				screen.Resize(cols, rows)
+			}
+		}
+	}
+	return screen, header, nil
+}
+
+// ExportCast renders a recording as a plain-text transcript or an HTML page
+func ExportCast(castPath, outPath, format string) error {
+	screen, header, err := RenderCast(castPath)
+	if err != nil {
+		return err
+	}
+
+	var out string
+	switch strings.ToLower(format) {
+	case ExportText:
+		out = screen.Text()
+	case ExportHTML:
+		title := header.Title
+		if title == "" {
+			title = strings.TrimSuffix(filepath.Base(castPath), filepath.Ext(castPath))
+		}
+		out = screen.HTML(title)
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+
+	return os.WriteFile(outPath, []byte(out), 0600)
+}
@@ -5,14 +5,32 @@ import (
 	"os"
 	"sync"
 	"time"
+
+	"Genpilot/internal/terminal"
 )
 
+// Options control how terminal traffic is written to the log
+type Options struct {
+	StripANSI bool  // drop escape sequences so the log reads as plain text
+	LogInput  bool  // also record what the user typed
+	MaxSize   int64 // rotate once the file reaches this many bytes, 0 disables
+	// MaxBackups is how many rotated logs are kept, DefaultMaxBackups if 0
+	MaxBackups int
+}
+
+// DefaultMaxBackups is how many rotated logs are kept unless configured
+const DefaultMaxBackups = 5
+
 // Logger handles connection and session logging
 type Logger struct {
 	mu       sync.Mutex
 	file     *os.File
 	enabled  bool
 	filePath string
+	opts     Options
+	stripper terminal.Stripper
+	size     int64
+	stuck    bool // rotation failed; keep appending to the current file
 }
 
 // NewLogger creates a new logger
@@ -20,6 +38,13 @@ func NewLogger() *Logger {
 	return &Logger{}
 }
 
+// SetOptions changes how terminal traffic is logged
+func (l *Logger) SetOptions(opts Options) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.opts = opts
+}
+
 // Start begins logging to a file
 func (l *Logger) Start(filePath string) error {
 	l.mu.Lock()
@@ -29,23 +54,100 @@ func (l *Logger) Start(filePath string) error {
 		l.file.Close()
 	}
 
-	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
+	if err := l.open(filePath); err != nil {
 		return err
 	}
 
-	l.file = f
 	l.filePath = filePath
 	l.enabled = true
+	l.stuck = false
+	l.stripper = terminal.Stripper{}
+	return nil
+}
+
+// open opens filePath for appending and writes the session header. Writes
+// go straight to the file, so the log can be read while it's being written.
+// Logs may hold typed input, so only the owner can read them.
+func (l *Logger) open(filePath string) error {
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	l.file = f
+	l.size = info.Size()
 
 	// Write header
 	header := fmt.Sprintf("\n=== Genpilot Session Log ===\n=== Started: %s ===\n\n",
 		time.Now().Format("2006-01-02 15:04:05"))
-	l.file.WriteString(header)
+	l.write([]byte(header))
 
 	return nil
 }
 
+func (l *Logger) write(p []byte) {
+	n, _ := l.file.Write(p)
+	l.size += int64(n)
+}
+
+// rotate moves a full log aside as <path>.1, shifting older ones up to
+// <path>.<MaxBackups> and deleting the oldest, and starts a new one. If that
+// fails the error is noted in the log, which then just keeps growing.
+func (l *Logger) rotate() {
+	if l.opts.MaxSize <= 0 || l.size < l.opts.MaxSize || l.stuck {
+		return
+	}
+
+	if err := l.shiftBackups(); err != nil {
+		l.stuck = true
+		l.write([]byte(fmt.Sprintf("\n=== Log rotation failed: %v ===\n", err)))
+		return
+	}
+
+	l.file.Close()
+	l.file = nil
+	if err := os.Rename(l.filePath, l.backup(1)); err != nil {
+		l.stuck = true
+	}
+	if err := l.open(l.filePath); err != nil {
+		l.enabled = false
+		return
+	}
+	if l.stuck {
+		l.write([]byte("\n=== Log rotation failed: could not move the log aside ===\n"))
+	}
+}
+
+// shiftBackups deletes the oldest rotated log and renames the others up by
+// one, making room for <path>.1
+func (l *Logger) shiftBackups() error {
+	keep := l.opts.MaxBackups
+	if keep <= 0 {
+		keep = DefaultMaxBackups
+	}
+
+	if err := os.Remove(l.backup(keep)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := keep - 1; i >= 1; i-- {
+		if err := os.Rename(l.backup(i), l.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// backup is the name of the i-th most recent rotated log
+func (l *Logger) backup(i int) string {
+	return fmt.Sprintf("%s.%d", l.filePath, i)
+}
+
 // Stop stops logging
 func (l *Logger) Stop() {
 	l.mu.Lock()
@@ -70,7 +172,8 @@ func (l *Logger) Write(text string) {
 	}
 
 	timestamp := time.Now().Format("15:04:05")
-	l.file.WriteString(fmt.Sprintf("[%s] %s\n", timestamp, text))
+	l.write([]byte(fmt.Sprintf("[%s] %s\n", timestamp, text)))
+	l.rotate()
 }
 
 // WriteRaw writes raw text without timestamp
@@ -82,7 +185,38 @@ func (l *Logger) WriteRaw(text string) {
 		return
 	}
 
-	l.file.WriteString(text)
+	l.write([]byte(text))
+	l.rotate()
+}
+
+// WriteOutput logs terminal output, stripping escape sequences if configured
+func (l *Logger) WriteOutput(p []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.enabled || l.file == nil {
+		return
+	}
+
+	if l.opts.StripANSI {
+		p = l.stripper.Strip(p)
+	}
+	l.write(p)
+	l.rotate()
+}
+
+// WriteInput logs user input on its own line when input logging is enabled
+func (l *Logger) WriteInput(p []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.enabled || l.file == nil || !l.opts.LogInput {
+		return
+	}
+
+	timestamp := time.Now().Format("15:04:05")
+	l.write([]byte(fmt.Sprintf("\n[%s] input: %q\n", timestamp, p)))
+	l.rotate()
 }
 
 // IsEnabled returns whether logging is active
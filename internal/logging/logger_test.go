@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLoggerWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.log")
+	l := NewLogger()
+	l.SetOptions(Options{StripANSI: true})
+	if err := l.Start(path); err != nil {
+		t.Fatal(err)
+	}
+	l.WriteOutput([]byte("\x1b[31mred\x1b[0m\r\n"))
+	l.WriteInput([]byte("secret\r"))
+	l.Write("note")
+	l.Stop()
+
+	if l.IsEnabled() {
+		t.Error("Still enabled after Stop")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	for _, want := range []string{"Genpilot Session Log", "red\r\n", "] note\n", "Session ended"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("Log missing %q:\n%s", want, log)
+		}
+	}
+	if strings.Contains(log, "\x1b[") || strings.Contains(log, "secret") {
+		t.Errorf("Log has escapes or input without LogInput:\n%s", log)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("Log mode %o, want 600", perm)
+		}
+	}
+}
+
+func TestLoggerRotates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.log")
+	l := NewLogger()
+	l.SetOptions(Options{MaxSize: 100, MaxBackups: 2})
+	if err := l.Start(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range "abcde" {
+		l.WriteRaw(strings.Repeat(string(c), 150))
+	}
+	l.Stop()
+
+	matches, _ := filepath.Glob(path + "*")
+	if len(matches) != 3 {
+		t.Fatalf("Files %q, want the log and 2 backups", matches)
+	}
+	// Newest first: the live log is empty but for its header, .1 holds the
+	// last write and .2 the one before; older ones are gone
+	for name, want := range map[string]string{path + ".1": "eee", path + ".2": "ddd"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s doesn't hold %q", filepath.Base(name), want)
+		}
+	}
+}
+
+func TestLoggerRotationFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.log")
+	// A directory in the way of the oldest backup can't be removed
+	if err := os.MkdirAll(filepath.Join(path+".1", "x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLogger()
+	l.SetOptions(Options{MaxSize: 10, MaxBackups: 1})
+	if err := l.Start(path); err != nil {
+		t.Fatal(err)
+	}
+	l.WriteRaw("first write\n")
+	l.WriteRaw("second write\n")
+	l.Stop()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	if strings.Count(log, "Log rotation failed") != 1 {
+		t.Errorf("Want one rotation failure noted:\n%s", log)
+	}
+	if !strings.Contains(log, "first write") || !strings.Contains(log, "second write") {
+		t.Errorf("Output lost after rotation failed:\n%s", log)
+	}
+}
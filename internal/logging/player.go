@@ -0,0 +1,266 @@
+package logging
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// PlayerState is reported whenever playback starts, stops or jumps
+type PlayerState struct {
+	Playing  bool    `json:"playing"`
+	Position float64 `json:"position"` // seconds
+	Duration float64 `json:"duration"`
+	Speed    float64 `json:"speed"`
+	Ended    bool    `json:"ended"`
+}
+
+// PlayerCallbacks deliver playback to a terminal view. Any may be nil.
+// They run while the player is locked and must not call back into it.
+type PlayerCallbacks struct {
+	Output func(data string)
+	Resize func(cols, rows int)
+	Reset  func() // the view must be cleared before a seek replays output
+	State  func(PlayerState)
+}
+
+// Player replays an asciicast recording in real time
+type Player struct {
+	mu        sync.Mutex
+	Header    CastHeader
+	events    []CastEvent
+	cb        PlayerCallbacks
+	pos       int       // next event to play
+	offset    float64   // recording time when playback last (re)started
+	resumedAt time.Time // wall time when playback last (re)started
+	playing   bool
+	speed     float64
+	closed    bool
+	wake      chan struct{}
+}
+
+// OpenPlayer loads a recording for playback. It starts paused at the beginning.
+func OpenPlayer(path string, cb PlayerCallbacks) (*Player, error) {
+	header, events, err := ReadCast(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Player{
+		Header: header,
+		events: events,
+		cb:     cb,
+		speed:  1,
+		wake:   make(chan struct{}, 1),
+	}
+	go p.loop()
+	return p, nil
+}
+
+// Duration returns the length of the recording in seconds
+func (p *Player) Duration() float64 {
+	if len(p.events) == 0 {
+		return 0
+	}
+	return p.events[len(p.events)-1].Time
+}
+
+// position returns the current recording time; callers hold p.mu
+func (p *Player) position() float64 {
+	if !p.playing {
+		return p.offset
+	}
+	return p.offset + time.Since(p.resumedAt).Seconds()*p.speed
+}
+
+// state returns the playback state; callers hold p.mu
+func (p *Player) state() PlayerState {
+	pos := p.position()
+	if d := p.Duration(); pos > d {
+		pos = d
+	}
+	return PlayerState{
+		Playing:  p.playing,
+		Position: pos,
+		Duration: p.Duration(),
+		Speed:    p.speed,
+		Ended:    p.pos >= len(p.events),
+	}
+}
+
+func (p *Player) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Play starts or resumes playback, restarting if the recording has ended
+func (p *Player) Play() {
+	p.mu.Lock()
+	if p.pos >= len(p.events) {
+		p.seek(0)
+	}
+	if !p.playing {
+		p.playing = true
+		p.resumedAt = time.Now()
+	}
+	state := p.state()
+	p.mu.Unlock()
+
+	p.report(state)
+	p.notify()
+}
+
+// Pause stops playback at the current position
+func (p *Player) Pause() {
+	p.mu.Lock()
+	if p.playing {
+		p.offset = p.position()
+		p.playing = false
+	}
+	state := p.state()
+	p.mu.Unlock()
+
+	p.report(state)
+	p.notify()
+}
+
+// SetSpeed changes the playback rate, e.g. 2 for double speed
+func (p *Player) SetSpeed(speed float64) {
+	if speed <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	p.offset = p.position()
+	p.resumedAt = time.Now()
+	p.speed = speed
+	state := p.state()
+	p.mu.Unlock()
+
+	p.report(state)
+	p.notify()
+}
+
+// Seek jumps to a position in seconds by clearing the view and replaying
+// all output up to that point at once
+func (p *Player) Seek(seconds float64) {
+	p.mu.Lock()
+	p.seek(seconds)
+	state := p.state()
+	p.mu.Unlock()
+
+	p.report(state)
+	p.notify()
+}
+
+// seek rebuilds the view at the given time; callers hold p.mu
+func (p *Player) seek(seconds float64) {
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	if p.cb.Reset != nil {
+		p.cb.Reset()
+	}
+
+	var out strings.Builder
+	i := 0
+	for ; i < len(p.events) && p.events[i].Time <= seconds; i++ {
+		p.apply(p.events[i], &out)
+	}
+	if out.Len() > 0 && p.cb.Output != nil {
+		p.cb.Output(out.String())
+	}
+
+	p.pos = i
+	p.offset = seconds
+	p.resumedAt = time.Now()
+}
+
+// apply plays one event, collecting output into out so it can be sent in
+// one piece; resizes flush the output collected before them
+func (p *Player) apply(e CastEvent, out *strings.Builder) {
+	switch e.Type {
+	case CastOutput:
+		out.WriteString(e.Data)
+	case CastResize:
+		cols, rows, err := ParseResize(e.Data)
+		if err != nil {
+			return
+		}
+		if out.Len() > 0 && p.cb.Output != nil {
+			p.cb.Output(out.String())
+			out.Reset()
+		}
+		if p.cb.Resize != nil {
+			p.cb.Resize(cols, rows)
+		}
+	}
+}
+
+func (p *Player) report(state PlayerState) {
+	if p.cb.State != nil {
+		p.cb.State(state)
+	}
+}
+
+// Close stops playback for good
+func (p *Player) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.playing = false
+	p.mu.Unlock()
+	p.notify()
+}
+
+func (p *Player) loop() {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return
+		}
+
+		var wait time.Duration = -1
+		if p.playing {
+			if p.pos >= len(p.events) {
+				p.offset = p.Duration()
+				p.playing = false
+				state := p.state()
+				p.mu.Unlock()
+				p.report(state)
+				continue
+			}
+
+			// Play everything that is due, then sleep until the next event
+			var out strings.Builder
+			now := p.position()
+			for p.pos < len(p.events) && p.events[p.pos].Time <= now {
+				p.apply(p.events[p.pos], &out)
+				p.pos++
+			}
+			if out.Len() > 0 && p.cb.Output != nil {
+				p.cb.Output(out.String())
+			}
+			if p.pos < len(p.events) {
+				wait = time.Duration((p.events[p.pos].Time - now) / p.speed * float64(time.Second))
+			} else {
+				wait = 0
+			}
+		}
+		p.mu.Unlock()
+
+		if wait < 0 {
+			<-p.wake
+			continue
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-p.wake:
+			timer.Stop()
+		}
+	}
+}
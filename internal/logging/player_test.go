@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// castFile writes a short recording and returns its path
+func castFile(t *testing.T) string {
+	t.Helper()
+	cast := `{"version":2,"width":80,"height":24}
+[0.00, "o", "one "]
+[0.02, "o", "two "]
+[0.03, "r", "100x30"]
+[0.05, "o", "three"]
+`
+	path := filepath.Join(t.TempDir(), "play.cast")
+	if err := os.WriteFile(path, []byte(cast), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// view collects what a player sends to the terminal
+type view struct {
+	mu      sync.Mutex
+	out     strings.Builder
+	resizes []string
+	resets  int
+	ended   chan struct{}
+}
+
+func (v *view) callbacks() PlayerCallbacks {
+	return PlayerCallbacks{
+		Output: func(data string) {
+			v.mu.Lock()
+			v.out.WriteString(data)
+			v.mu.Unlock()
+		},
+		Resize: func(cols, rows int) {
+			v.mu.Lock()
+			v.resizes = append(v.resizes, fmt.Sprintf("%dx%d", cols, rows))
+			v.mu.Unlock()
+		},
+		Reset: func() {
+			v.mu.Lock()
+			v.out.Reset()
+			v.resets++
+			v.mu.Unlock()
+		},
+		State: func(s PlayerState) {
+			if s.Ended && !s.Playing {
+				select {
+				case v.ended <- struct{}{}:
+				default:
+				}
+			}
+		},
+	}
+}
+
+func TestPlayerPlays(t *testing.T) {
+	v := &view{ended: make(chan struct{}, 1)}
+	p, err := OpenPlayer(castFile(t), v.callbacks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	if d := p.Duration(); d != 0.05 {
+		t.Errorf("Duration %v", d)
+	}
+	p.SetSpeed(2)
+	p.Play()
+	select {
+	case <-v.ended:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Playback didn't end")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if got := v.out.String(); got != "one two three" {
+		t.Errorf("Output %q", got)
+	}
+	if len(v.resizes) != 1 || v.resizes[0] != "100x30" {
+		t.Errorf("Resizes %q", v.resizes)
+	}
+}
+
+func TestPlayerSeek(t *testing.T) {
+	v := &view{ended: make(chan struct{}, 1)}
+	p, err := OpenPlayer(castFile(t), v.callbacks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	// Paused, a seek replays everything up to the position at once
+	p.Seek(0.04)
+	v.mu.Lock()
+	if got := v.out.String(); got != "one two " || v.resets != 1 || len(v.resizes) != 1 {
+		t.Errorf("After seek: output %q, %d resets, resizes %q", got, v.resets, v.resizes)
+	}
+	v.mu.Unlock()
+
+	// Seeking back clears the view first
+	p.Seek(0.01)
+	v.mu.Lock()
+	if got := v.out.String(); got != "one " || v.resets != 2 {
+		t.Errorf("After seeking back: output %q, %d resets", got, v.resets)
+	}
+	v.mu.Unlock()
+}
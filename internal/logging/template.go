@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultTemplate names logs after the session and when logging started
+const DefaultTemplate = "{name}_{date}_{time}.log"
+
+// TemplateVars are substituted into log file name templates
+type TemplateVars struct {
+	Name string
+	Host string
+	User string
+}
+
+// unsafeChars can't appear in file names on at least one platform
+var unsafeChars = strings.NewReplacer(
+	"/", "_", "\\", "_", ":", "_", "*", "_", "?", "_",
+	"\"", "_", "<", "_", ">", "_", "|", "_",
+)
+
+// ExpandTemplate fills {name}, {host}, {user}, {date} and {time} in a log
+// file template. "~/" expands to the home directory and relative results
+// are placed in dir.
+func ExpandTemplate(template, dir string, vars TemplateVars, now time.Time) string {
+	if template == "" {
+		template = DefaultTemplate
+	}
+
+	path := strings.NewReplacer(
+		"{name}", unsafeChars.Replace(vars.Name),
+		"{host}", unsafeChars.Replace(vars.Host),
+		"{user}", unsafeChars.Replace(vars.User),
+		"{date}", now.Format("2006-01-02"),
+		"{time}", now.Format("150405"),
+	).Replace(template)
+
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return path
+}
+
+// DefaultDir returns the directory session logs go to by default
+func DefaultDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".genpilot", "logs"), nil
+}
+
+// DefaultRecordingDir returns the directory asciicast recordings go to
+func DefaultRecordingDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".genpilot", "recordings"), nil
+}
@@ -0,0 +1,34 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	now := time.Date(2024, 3, 9, 14, 5, 7, 0, time.UTC)
+	vars := TemplateVars{Name: "web/prod", Host: "10.0.0.1", User: "root"}
+	dir := filepath.FromSlash("/logs")
+	home, _ := os.UserHomeDir()
+
+	tests := []struct {
+		template, want string
+	}{
+		{"", filepath.Join(dir, "web_prod_2024-03-09_140507.log")},
+		{"{user}@{host}.log", filepath.Join(dir, "root@10.0.0.1.log")},
+		{"{host}/{date}.txt", filepath.Join(dir, "10.0.0.1", "2024-03-09.txt")},
+		{"~/x/{name}.log", filepath.Join(home, "x", "web_prod.log")},
+	}
+	for _, tt := range tests {
+		if got := ExpandTemplate(tt.template, dir, vars, now); got != tt.want {
+			t.Errorf("ExpandTemplate(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+
+	abs := filepath.Join(t.TempDir(), "{name}.log")
+	if got, want := ExpandTemplate(abs, dir, vars, now), filepath.Join(filepath.Dir(abs), "web_prod.log"); got != want {
+		t.Errorf("Absolute template = %q, want %q", got, want)
+	}
+}
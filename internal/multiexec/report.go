@@ -0,0 +1,82 @@
+package multiexec
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+)
+
+// Group is a set of hosts that produced identical results
+type Group struct {
+	Hosts    []string `json:"hosts"`
+	Stdout   string   `json:"stdout"`
+	Stderr   string   `json:"stderr"`
+	ExitCode int      `json:"exit_code"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Report is the exportable record of a run
+type Report struct {
+	Command   string       `json:"command"`
+	StartTime string       `json:"start_time"`
+	EndTime   string       `json:"end_time"`
+	Results   []HostResult `json:"results"`
+	Groups    []Group      `json:"groups"`
+}
+
+// NewReport builds a report with results grouped by identical output
+func NewReport(command string, start time.Time, results []HostResult) Report {
+	return Report{
+		Command:   command,
+		StartTime: start.Format(time.RFC3339),
+		EndTime:   time.Now().Format(time.RFC3339),
+		Results:   results,
+		Groups:    Summarize(results),
+	}
+}
+
+// Summarize groups hosts whose output, exit code and error are identical.
+// Larger groups come first so outliers stand out at the end.
+func Summarize(results []HostResult) []Group {
+	type key struct {
+		stdout, stderr, err string
+		code                int
+	}
+
+	index := make(map[key]int)
+	var groups []Group
+	for _, r := range results {
+		k := key{r.Stdout, r.Stderr, r.Error, r.ExitCode}
+		host := r.Name
+		if host == "" {
+			host = r.Host
+		}
+		if i, ok := index[k]; ok {
+			groups[i].Hosts = append(groups[i].Hosts, host)
+			continue
+		}
+		index[k] = len(groups)
+		groups = append(groups, Group{
+			Hosts:    []string{host},
+			Stdout:   r.Stdout,
+			Stderr:   r.Stderr,
+			ExitCode: r.ExitCode,
+			Error:    r.Error,
+		})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].Hosts) > len(groups[j].Hosts)
+	})
+	return groups
+}
+
+// WriteJSON exports the report to a file
+func (r Report) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,28 @@
+package multiexec
+
+import (
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	results := []HostResult{
+		{Name: "web1", Stdout: "ok\n", ExitCode: 0},
+		{Name: "web2", Stdout: "disk full\n", ExitCode: 1},
+		{Name: "web3", Stdout: "ok\n", ExitCode: 0},
+		{Host: "10.0.0.4", Stdout: "ok\n", ExitCode: 0},
+		{Name: "web5", ExitCode: -1, Error: "connect: timeout"},
+	}
+
+	groups := Summarize(results)
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 groups, got %d", len(groups))
+	}
+
+	first := groups[0]
+	if len(first.Hosts) != 3 || first.Hosts[0] != "web1" || first.Hosts[2] != "10.0.0.4" {
+		t.Errorf("Unexpected largest group %v", first.Hosts)
+	}
+	if groups[1].Hosts[0] != "web2" || groups[2].Hosts[0] != "web5" {
+		t.Errorf("Outliers out of order: %v, %v", groups[1].Hosts, groups[2].Hosts)
+	}
+}
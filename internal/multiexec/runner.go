@@ -0,0 +1,243 @@
+package multiexec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	sshclient "Genpilot/internal/ssh"
+)
+
+// Stream names passed to output callbacks
+const (
+	Stdout = "stdout"
+	Stderr = "stderr"
+)
+
+// maxCapture bounds how much output is kept per host and stream
+const maxCapture = 1 << 20
+
+// Target is a host to run a command on
+type Target struct {
+	Name     string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	KeyPath  string
+}
+
+// Options controls how a command is run across targets
+type Options struct {
+	Concurrency int           // hosts running at once, defaults to 10
+	Timeout     time.Duration // per host, including connect; 0 means none
+}
+
+// Callbacks receive progress while a run is in flight. They are called
+// from several goroutines at once; either may be nil.
+type Callbacks struct {
+	Output func(target Target, stream string, data []byte)
+	Done   func(result HostResult)
+}
+
+// HostResult is the outcome of a command on one host
+type HostResult struct {
+	Name      string `json:"name"`
+	Host      string `json:"host"`
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	ExitCode  int    `json:"exit_code"`
+	Error     string `json:"error,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+	StartTime string `json:"start_time"`
+	Duration  int64  `json:"duration_ms"`
+}
+
+// dial connects to a target and authenticates
+func dial(t Target, timeout time.Duration) (*sshclient.Client, error) {
+	var client *sshclient.Client
+	var err error
+	if t.KeyPath != "" {
+		client, err = sshclient.NewClientWithKey(t.User, t.KeyPath, t.Host, t.Port, timeout, nil)
+	} else {
+		client, err = sshclient.NewClient(t.User, t.Password, t.Host, t.Port, timeout, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Connect(fmt.Sprintf("%s:%d", t.Host, t.Port)); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// Run executes command on every target with bounded concurrency and
+// returns the results in target order once all hosts have finished.
+func Run(ctx context.Context, command string, targets []Target, opts Options, cb Callbacks) []HostResult {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 10
+	}
+
+	results := make([]HostResult, len(targets))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				results[i] = runHost(ctx, command, t, opts, cb)
+			case <-ctx.Done():
+				results[i] = HostResult{
+					Name:     t.Name,
+					Host:     t.Host,
+					ExitCode: -1,
+					Error:    "cancelled",
+				}
+			}
+
+			if cb.Done != nil {
+				cb.Done(results[i])
+			}
+		}(i, t)
+	}
+
+	wg.Wait()
+	return results
+}
+
+type outcome struct {
+	code int
+	err  error
+}
+
+func runHost(ctx context.Context, command string, t Target, opts Options, cb Callbacks) HostResult {
+	start := time.Now()
+	result := HostResult{
+		Name:      t.Name,
+		Host:      t.Host,
+		ExitCode:  -1,
+		StartTime: start.Format(time.RFC3339),
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	stdout := &capture{target: t, stream: Stdout, emit: cb.Output}
+	stderr := &capture{target: t, stream: Stderr, emit: cb.Output}
+
+	// Closing the client is the only way to abort a running command
+	var mu sync.Mutex
+	var client *sshclient.Client
+	aborted := false
+
+	done := make(chan outcome, 1)
+	go func() {
+		c, err := dial(t, opts.Timeout)
+		if err != nil {
+			done <- outcome{-1, fmt.Errorf("connect: %w", err)}
+			return
+		}
+
+		mu.Lock()
+		if aborted {
+			mu.Unlock()
+			c.Close()
+			return
+		}
+		client = c
+		mu.Unlock()
+		defer c.Close()
+
+		code, err := c.ExecCommand(command, stdout, stderr)
+		done <- outcome{code, err}
+	}()
+
+	select {
+	case o := <-done:
+		result.ExitCode = o.code
+		if o.err != nil {
+			result.Error = o.err.Error()
+		}
+	case <-ctx.Done():
+		mu.Lock()
+		aborted = true
+		if client != nil {
+			client.Close()
+		}
+		mu.Unlock()
+
+		if ctx.Err() == context.DeadlineExceeded {
+			result.Error = fmt.Sprintf("timed out after %s", opts.Timeout)
+		} else {
+			result.Error = "cancelled"
+		}
+	}
+
+	// An abandoned command may still be writing; it must not report
+	// output after the host's result
+	stdout.stop()
+	stderr.stop()
+
+	result.Stdout, result.Truncated = stdout.String()
+	var truncated bool
+	result.Stderr, truncated = stderr.String()
+	result.Truncated = result.Truncated || truncated
+	result.Duration = time.Since(start).Milliseconds()
+	return result
+}
+
+// capture keeps a bounded copy of a stream while forwarding it to a callback
+type capture struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	truncated bool
+	stopped   bool
+	target    Target
+	stream    string
+	emit      func(Target, string, []byte)
+}
+
+// Write keeps and forwards p. The lock is held while forwarding so stop
+// can't return with a callback still in flight.
+func (c *capture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return len(p), nil
+	}
+	if room := maxCapture - c.buf.Len(); room < len(p) {
+		c.buf.Write(p[:max(room, 0)])
+		c.truncated = true
+	} else {
+		c.buf.Write(p)
+	}
+
+	if c.emit != nil {
+		c.emit(c.target, c.stream, append([]byte(nil), p...))
+	}
+	return len(p), nil
+}
+
+// stop drops everything written from now on
+func (c *capture) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped = true
+}
+
+func (c *capture) String() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.String(), c.truncated
+}
@@ -0,0 +1,55 @@
+package multiexec
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCaptureStopWaitsForOutput(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var emitted []string
+	c := &capture{stream: Stdout, emit: func(_ Target, _ string, data []byte) {
+		emitted = append(emitted, string(data))
+		if len(emitted) == 1 {
+			close(entered)
+			<-release
+		}
+	}}
+
+	go c.Write([]byte("first"))
+	<-entered
+
+	// A host that gives up on its command stops the capture; output being
+	// forwarded right then must finish before its result is reported
+	stopped := make(chan struct{})
+	go func() {
+		c.stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("stop returned while output was being forwarded")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-stopped
+
+	c.Write([]byte("late"))
+	if len(emitted) != 1 {
+		t.Errorf("Emitted %q after stop", emitted)
+	}
+	if out, _ := c.String(); out != "first" {
+		t.Errorf("Captured %q", out)
+	}
+}
+
+func TestCaptureTruncates(t *testing.T) {
+	c := &capture{}
+	c.Write(make([]byte, maxCapture-1))
+	c.Write([]byte("xyz"))
+	out, truncated := c.String()
+	if len(out) != maxCapture || !truncated {
+		t.Errorf("Kept %d bytes, truncated %v", len(out), truncated)
+	}
+}
@@ -0,0 +1,104 @@
+// Package mux lists and attaches to tmux and screen sessions on a host
+package mux
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Terminal multiplexers
+const (
+	Tmux   = "tmux"
+	Screen = "screen"
+)
+
+// ListTmux lists tmux sessions with their details first and the name last,
+// since names may contain spaces
+const ListTmux = `tmux list-sessions -F '#{session_windows} #{session_attached} #{session_created} #{session_name}'`
+
+// ListScreen lists screen sessions
+const ListScreen = `screen -ls`
+
+// Session is a multiplexer session running on a host
+type Session struct {
+	Tool     string `json:"tool"`
+	Name     string `json:"name"`              // what to attach to; pid.name for screen
+	Windows  int    `json:"windows,omitempty"` // tmux only
+	Attached bool   `json:"attached"`
+	Created  string `json:"created,omitempty"`
+}
+
+// ParseTmux reads the output of ListTmux
+func ParseTmux(out string) []Session {
+	var sessions []Session
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(strings.TrimRight(line, "\r"), " ", 4)
+		if len(fields) != 4 || fields[3] == "" {
+			continue
+		}
+		windows, err1 := strconv.Atoi(fields[0])
+		attached, err2 := strconv.Atoi(fields[1])
+		created, err3 := strconv.ParseInt(fields[2], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			// Not list-sessions output, e.g. "no server running"
+			continue
+		}
+		sessions = append(sessions, Session{
+			Tool:     Tmux,
+			Name:     fields[3],
+			Windows:  windows,
+			Attached: attached > 0,
+			Created:  time.Unix(created, 0).Format(time.RFC3339),
+		})
+	}
+	return sessions
+}
+
+// ParseScreen reads the output of ListScreen. Sessions are tab-indented
+// lines of pid.name, an optional start date and the state in parentheses.
+func ParseScreen(out string) []Session {
+	var sessions []Session
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "\t") {
+			continue
+		}
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		pid, _, ok := strings.Cut(fields[0], ".")
+		if !ok {
+			continue
+		}
+		if _, err := strconv.Atoi(pid); err != nil {
+			continue
+		}
+
+		s := Session{Tool: Screen, Name: fields[0]}
+		for _, f := range fields[1:] {
+			switch {
+			case f == "(Attached)" || f == "(Multi, attached)":
+				s.Attached = true
+			case f == "(Detached)" || f == "(Multi, detached)":
+			case strings.HasPrefix(f, "(") && strings.HasSuffix(f, ")"):
+				s.Created = strings.Trim(f, "()")
+			}
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// AttachCommand returns the command that attaches to the named session,
+// creating it if it doesn't exist. Other clients of the session are
+// detached for screen but stay for tmux.
+func AttachCommand(tool, name string) string {
+	q := quote(name)
+	if tool == Screen {
+		return "screen -d -r " + q + " || screen -S " + q
+	}
+	return "tmux new-session -A -s " + q
+}
+
+// quote makes s a single shell word
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,51 @@
+package mux
+
+import "testing"
+
+func TestParseTmux(t *testing.T) {
+	out := "3 1 1700000000 main\n1 0 1700000100 long job\n"
+	got := ParseTmux(out)
+	if len(got) != 2 {
+		t.Fatalf("Parsed %+v", got)
+	}
+	if got[0].Name != "main" || got[0].Windows != 3 || !got[0].Attached {
+		t.Errorf("First session %+v", got[0])
+	}
+	if got[1].Name != "long job" || got[1].Attached {
+		t.Errorf("Second session %+v", got[1])
+	}
+
+	if got := ParseTmux("no server running on /tmp/tmux-1000/default\n"); len(got) != 0 {
+		t.Errorf("Parsed an error message: %+v", got)
+	}
+}
+
+func TestParseScreen(t *testing.T) {
+	out := "There are screens on:\n" +
+		"\t12345.pts-0.host\t(Detached)\n" +
+		"\t23456.build\t(01/02/2024 10:00:00 AM)\t(Attached)\n" +
+		"2 Sockets in /run/screen/S-bob.\n"
+	got := ParseScreen(out)
+	if len(got) != 2 {
+		t.Fatalf("Parsed %+v", got)
+	}
+	if got[0].Name != "12345.pts-0.host" || got[0].Attached {
+		t.Errorf("First session %+v", got[0])
+	}
+	if got[1].Name != "23456.build" || !got[1].Attached || got[1].Created != "01/02/2024 10:00:00 AM" {
+		t.Errorf("Second session %+v", got[1])
+	}
+
+	if got := ParseScreen("No Sockets found in /run/screen/S-bob.\n"); len(got) != 0 {
+		t.Errorf("Parsed %+v", got)
+	}
+}
+
+func TestAttachCommand(t *testing.T) {
+	if got := AttachCommand(Tmux, "bob's"); got != `tmux new-session -A -s 'bob'\''s'` {
+		t.Errorf("tmux: %s", got)
+	}
+	if got := AttachCommand(Screen, "work"); got != `screen -d -r 'work' || screen -S 'work'` {
+		t.Errorf("screen: %s", got)
+	}
+}
@@ -6,6 +6,11 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"Genpilot/internal/fileinfo"
 
 	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
@@ -14,6 +19,11 @@ import (
 // Client represents an SFTP connection wrapper
 type Client struct {
 	client *sftp.Client
+
+	idsMu  sync.Mutex
+	users  map[uint32]string
+	groups map[uint32]string
+	idsAt  time.Time
 }
 
 // NewClient creates a new SFTP client from an existing SSH client
@@ -41,6 +51,104 @@ func (c *Client) ListDirectory(dirPath string) ([]os.FileInfo, error) {
 	return c.client.ReadDir(dirPath)
 }
 
+// Entry is a directory entry with its symlink resolved. Info describes the
+// entry itself; for a symlink Target describes what it points to, or is
+// nil when the link is broken.
+type Entry struct {
+	Info       os.FileInfo
+	LinkTarget string
+	Target     os.FileInfo
+}
+
+// IsLink reports whether the entry is a symlink
+func (e Entry) IsLink() bool {
+	return e.Info.Mode()&os.ModeSymlink != 0
+}
+
+// Broken reports whether the entry is a symlink to nothing
+func (e Entry) Broken() bool {
+	return e.IsLink() && e.Target == nil
+}
+
+// linkLookups bounds the symlinks resolved at once while listing
+const linkLookups = 16
+
+// ListEntries lists a directory like ListDirectory and resolves each
+// symlink's target
+func (c *Client) ListEntries(dirPath string) ([]Entry, error) {
+	infos, err := c.client.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, len(infos))
+	sem := make(chan struct{}, linkLookups)
+	var wg sync.WaitGroup
+	for i, info := range infos {
+		entries[i].Info = info
+		if info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(e *Entry) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			p := path.Join(dirPath, e.Info.Name())
+			e.LinkTarget, _ = c.client.ReadLink(p)
+			if target, err := c.client.Stat(p); err == nil {
+				e.Target = target
+			}
+		}(&entries[i])
+	}
+	wg.Wait()
+	return entries, nil
+}
+
+// Owner returns the owner and group ids the server reported for a file
+func Owner(info os.FileInfo) (uid, gid uint32, ok bool) {
+	st, ok := info.Sys().(*sftp.FileStat)
+	if !ok {
+		return 0, 0, false
+	}
+	return st.UID, st.GID, true
+}
+
+const (
+	// idsMaxAge is how long user and group names are cached
+	idsMaxAge = 5 * time.Minute
+	// idsMaxSize bounds how much of /etc/passwd and /etc/group is read
+	idsMaxSize = 4 << 20
+)
+
+// IDNames returns the server's user and group names by id, read from its
+// /etc/passwd and /etc/group and cached for a while. Hosts without those
+// files have no names.
+func (c *Client) IDNames() (users, groups map[uint32]string) {
+	c.idsMu.Lock()
+	defer c.idsMu.Unlock()
+
+	if c.users == nil || time.Since(c.idsAt) > idsMaxAge {
+		c.users = fileinfo.ParseIDs(c.readSmall("/etc/passwd"))
+		c.groups = fileinfo.ParseIDs(c.readSmall("/etc/group"))
+		c.idsAt = time.Now()
+	}
+	return c.users, c.groups
+}
+
+// readSmall reads a small remote file, nil if it can't be read
+func (c *Client) readSmall(remotePath string) []byte {
+	f, err := c.client.Open(remotePath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(f, idsMaxSize))
+	return data
+}
+
 // Upload uploads a local file to the remote server
 func (c *Client) Upload(localPath, remotePath string) error {
 	localFile, err := os.Open(localPath)
@@ -112,6 +220,83 @@ func (c *Client) Stat(remotePath string) (os.FileInfo, error) {
 	return c.client.Stat(remotePath)
 }
 
+// Lstat returns file info for a remote path without following a symlink
+func (c *Client) Lstat(remotePath string) (os.FileInfo, error) {
+	return c.client.Lstat(remotePath)
+}
+
+// ReadLink returns the target of a remote symlink
+func (c *Client) ReadLink(remotePath string) (string, error) {
+	return c.client.ReadLink(remotePath)
+}
+
+// Symlink creates a remote symlink at linkPath pointing to target
+func (c *Client) Symlink(target, linkPath string) error {
+	return c.client.Symlink(target, linkPath)
+}
+
+// Link creates a remote hard link; the server must support the
+// hardlink@openssh.com extension
+func (c *Client) Link(oldPath, newPath string) error {
+	if _, ok := c.client.HasExtension("hardlink@openssh.com"); !ok {
+		return fmt.Errorf("server does not support hard links")
+	}
+	return c.client.Link(oldPath, newPath)
+}
+
+// maxLinkHops bounds the symlinks followed by RealPath, as the kernel does
+const maxLinkHops = 40
+
+// RealPath resolves a remote path to an absolute one with symlinks, . and
+// .. removed. Links are followed here since not every server's realpath
+// does so.
+func (c *Client) RealPath(remotePath string) (string, error) {
+	abs, err := c.client.RealPath(remotePath)
+	if err != nil {
+		return "", err
+	}
+
+	resolved := "/"
+	rest := splitPath(abs)
+	for hops := 0; len(rest) > 0; {
+		next := path.Join(resolved, rest[0])
+		rest = rest[1:]
+
+		info, err := c.client.Lstat(next)
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		if hops++; hops > maxLinkHops {
+			return "", fmt.Errorf("%s: too many levels of symbolic links", remotePath)
+		}
+		target, err := c.client.ReadLink(next)
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(target) {
+			resolved = "/"
+		}
+		rest = append(splitPath(target), rest...)
+	}
+	return resolved, nil
+}
+
+// splitPath splits a slash-separated path into its elements
+func splitPath(p string) []string {
+	var parts []string
+	for _, part := range strings.Split(p, "/") {
+		if part != "" && part != "." {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
 // GetSFTPClient returns the underlying sftp.Client for direct use
 func (c *Client) GetSFTPClient() *sftp.Client {
 	return c.client
@@ -0,0 +1,186 @@
+package sftp
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"Genpilot/internal/fileinfo"
+
+	"github.com/pkg/sftp"
+)
+
+// pipeClient connects a client to an SFTP server on this machine
+func pipeClient(t *testing.T) *Client {
+	t.Helper()
+	cr, sw := io.Pipe()
+	sr, cw := io.Pipe()
+
+	server, err := sftp.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{sr, sw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve()
+
+	client, err := sftp.NewClientPipe(cr, cw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		// The server closes its end first so the client stops reading
+		server.Close()
+		client.Close()
+	})
+	return &Client{client: client}
+}
+
+func TestListEntriesResolvesLinks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file"), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{"to-sub": "sub", "to-file": "file", "dangling": "missing"} {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := pipeClient(t)
+	entries, err := c.ListEntries(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]Entry)
+	for _, e := range entries {
+		got[e.Info.Name()] = e
+	}
+	if len(got) != 5 {
+		t.Fatalf("got %d entries, want 5", len(got))
+	}
+	if e := got["sub"]; e.IsLink() || !e.Info.IsDir() {
+		t.Errorf("sub: link %v dir %v", e.IsLink(), e.Info.IsDir())
+	}
+	if e := got["to-sub"]; !e.IsLink() || e.Broken() || e.LinkTarget != "sub" || !e.Target.IsDir() {
+		t.Errorf("to-sub: link %v broken %v target %q", e.IsLink(), e.Broken(), e.LinkTarget)
+	}
+	if e := got["to-file"]; !e.IsLink() || e.Broken() || !e.Target.Mode().IsRegular() || e.Target.Size() != 4 {
+		t.Errorf("to-file: link %v broken %v", e.IsLink(), e.Broken())
+	}
+	if e := got["dangling"]; !e.Broken() || e.LinkTarget != "missing" {
+		t.Errorf("dangling: broken %v target %q", e.Broken(), e.LinkTarget)
+	}
+}
+
+func TestSymlinkAndRealPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	c := pipeClient(t)
+	link := filepath.Join(dir, "link")
+	if err := c.Symlink("sub", link); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := os.Readlink(link); err != nil || target != "sub" {
+		t.Fatalf("link points to %q (%v), want sub", target, err)
+	}
+
+	real, err := c.RealPath(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := filepath.EvalSymlinks(filepath.Join(dir, "sub"))
+	if real != want {
+		t.Errorf("real path %q, want %q", real, want)
+	}
+}
+
+func TestChmodAll(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.sh", "sub/b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("/", filepath.Join(sub, "root")); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := fileinfo.ParseMode("go-r")
+	dirs, _ := fileinfo.ParseMode("700")
+	c := pipeClient(t)
+
+	dry := c.ChmodAll([]string{dir, filepath.Join(dir, "missing")}, &files, &dirs, true, true)
+	if len(dry) != 5 {
+		t.Fatalf("Dry run %+v", dry)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, "a.sh")); info.Mode().Perm() != 0o644 {
+		t.Errorf("Dry run changed a.sh to %v", info.Mode())
+	}
+	if last := dry[len(dry)-1]; last.Error == "" || last.Path != filepath.Join(dir, "missing") {
+		t.Errorf("Missing path reported as %+v", last)
+	}
+
+	changes := c.ChmodAll([]string{dir}, &files, &dirs, true, false)
+	for _, ch := range changes {
+		if ch.Error != "" {
+			t.Errorf("%s: %s", ch.Path, ch.Error)
+		}
+	}
+	want := map[string]os.FileMode{".": 0o700, "a.sh": 0o600, "sub": 0o700, "sub/b.txt": 0o600}
+	for name, perm := range want {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil || info.Mode().Perm() != perm {
+			t.Errorf("%s is %v (%v), want %v", name, info.Mode().Perm(), err, perm)
+		}
+	}
+	if info, _ := os.Stat("/"); info.Mode().Perm() == 0o700 {
+		t.Error("Followed a symlink while descending")
+	}
+
+	if again := c.ChmodAll([]string{dir}, &files, &dirs, true, false); len(again) != 0 {
+		t.Errorf("Nothing left to change, got %+v", again)
+	}
+}
+
+func TestChownAll(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("chown needs root")
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := pipeClient(t)
+	if id, err := c.LookupID("root", false); err != nil || id != 0 {
+		t.Errorf("LookupID(root) = %d, %v", id, err)
+	}
+	if _, err := c.LookupID("no-such-user-here", false); err == nil {
+		t.Error("Looked up a missing user")
+	}
+
+	gid := uint32(1)
+	changes := c.ChownAll([]string{file}, nil, &gid, false, false)
+	if len(changes) != 1 || changes[0].Error != "" {
+		t.Fatalf("Changes %+v", changes)
+	}
+	info, _ := os.Stat(file)
+	if _, g, _ := fileinfo.Owner(info); g != 1 {
+		t.Errorf("Group is %d, want 1", g)
+	}
+}
@@ -0,0 +1,182 @@
+package sftp
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"Genpilot/internal/fileinfo"
+)
+
+// Change describes one file whose permissions or ownership change, or
+// would in a dry run. Error is set if the change failed.
+type Change struct {
+	Path  string `json:"path"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Error string `json:"error,omitempty"`
+}
+
+// target is a file to change, found by expanding the paths given
+type target struct {
+	path string
+	info os.FileInfo
+}
+
+// targets expands paths, descending into directories when recursive.
+// Symlinks named directly are followed, as chmod does; symlinks found
+// while descending are left alone. Files that can't be read are reported
+// as failed changes.
+func (c *Client) targets(paths []string, recursive bool) ([]target, []Change) {
+	var found []target
+	var failed []Change
+	for _, root := range paths {
+		info, err := c.client.Stat(root)
+		if err != nil {
+			failed = append(failed, Change{Path: root, Error: err.Error()})
+			continue
+		}
+		found = append(found, target{root, info})
+		if !recursive || !info.IsDir() {
+			continue
+		}
+
+		dir := root
+		if linfo, err := c.client.Lstat(root); err == nil && linfo.Mode()&os.ModeSymlink != 0 {
+			if dir, err = c.RealPath(root); err != nil {
+				failed = append(failed, Change{Path: root, Error: err.Error()})
+				continue
+			}
+		}
+
+		walker := c.client.Walk(dir)
+		for walker.Step() {
+			if err := walker.Err(); err != nil {
+				failed = append(failed, Change{Path: walker.Path(), Error: err.Error()})
+				continue
+			}
+			if walker.Path() == dir || walker.Stat().Mode()&os.ModeSymlink != 0 {
+				continue
+			}
+			found = append(found, target{walker.Path(), walker.Stat()})
+		}
+	}
+	return found, failed
+}
+
+// apply runs set on each change, children before their parents so taking
+// away a directory's permissions doesn't lock out what's inside
+func apply(changes []Change, set func(i int) error) {
+	for i := len(changes) - 1; i >= 0; i-- {
+		if err := set(i); err != nil {
+			changes[i].Error = err.Error()
+		}
+	}
+}
+
+// ChmodAll changes the permissions of paths. Files get fileMode and
+// directories dirMode; either may be nil to leave those alone. Only files
+// whose permissions differ are changed and reported; with dryRun nothing
+// is changed.
+func (c *Client) ChmodAll(paths []string, fileMode, dirMode *fileinfo.ModeSpec, recursive, dryRun bool) []Change {
+	found, failed := c.targets(paths, recursive)
+
+	var changes []Change
+	var modes []uint32
+	for _, t := range found {
+		spec := fileMode
+		if t.info.IsDir() {
+			spec = dirMode
+		}
+		if spec == nil {
+			continue
+		}
+		from := fileinfo.Perm(t.info.Mode())
+		to := spec.Apply(from, t.info.IsDir())
+		if to == from {
+			continue
+		}
+		changes = append(changes, Change{
+			Path: t.path,
+			From: fmt.Sprintf("%04o", from),
+			To:   fmt.Sprintf("%04o", to),
+		})
+		modes = append(modes, to)
+	}
+
+	if !dryRun {
+		apply(changes, func(i int) error {
+			return c.client.Chmod(changes[i].Path, fileinfo.FileMode(modes[i]))
+		})
+	}
+	return append(changes, failed...)
+}
+
+// ChownAll changes the owner and group of paths; a nil uid or gid keeps
+// that one. Only files whose ownership differs are changed and reported;
+// with dryRun nothing is changed.
+func (c *Client) ChownAll(paths []string, uid, gid *uint32, recursive, dryRun bool) []Change {
+	found, failed := c.targets(paths, recursive)
+	users, groups := c.IDNames()
+
+	var changes []Change
+	var ids [][2]uint32
+	for _, t := range found {
+		fromUID, fromGID, ok := Owner(t.info)
+		if !ok {
+			failed = append(failed, Change{Path: t.path, Error: "server did not report ownership"})
+			continue
+		}
+		toUID, toGID := fromUID, fromGID
+		if uid != nil {
+			toUID = *uid
+		}
+		if gid != nil {
+			toGID = *gid
+		}
+		if toUID == fromUID && toGID == fromGID {
+			continue
+		}
+		changes = append(changes, Change{
+			Path: t.path,
+			From: idName(users, fromUID) + ":" + idName(groups, fromGID),
+			To:   idName(users, toUID) + ":" + idName(groups, toGID),
+		})
+		ids = append(ids, [2]uint32{toUID, toGID})
+	}
+
+	if !dryRun {
+		apply(changes, func(i int) error {
+			return c.client.Chown(changes[i].Path, int(ids[i][0]), int(ids[i][1]))
+		})
+	}
+	return append(changes, failed...)
+}
+
+// LookupID resolves a user, or a group if group is set, given by name or
+// numeric id on the server
+func (c *Client) LookupID(name string, group bool) (uint32, error) {
+	if id, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return uint32(id), nil
+	}
+
+	users, groups := c.IDNames()
+	names, kind := users, "user"
+	if group {
+		names, kind = groups, "group"
+	}
+	for id, n := range names {
+		if n == name {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown %s %q", kind, name)
+}
+
+// idName is a user or group's name, or its number if it has none
+func idName(names map[uint32]string, id uint32) string {
+	if name := names[id]; name != "" {
+		return name
+	}
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,393 @@
+// Package share serves a live terminal to viewers in a browser. Each share
+// listens on its own address behind a random token; viewers watch over a
+// WebSocket and may type only when the owner allows it.
+package share
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// HistorySize bounds the output replayed to viewers who join late
+	HistorySize = 256 * 1024
+	// viewerQueue is how many messages a viewer may fall behind before it
+	// is dropped
+	viewerQueue  = 256
+	writeTimeout = 10 * time.Second
+	maxInput     = 4096
+)
+
+//go:embed viewer.html
+var viewerPage []byte
+
+// assets holds the xterm.js files the viewer page loads, copied from the
+// frontend's node_modules by its build so viewers fetch nothing from
+// elsewhere. Without them the page reports that it can't start.
+//
+//go:embed assets
+var assets embed.FS
+
+// assetTypes are the files served from assets
+var assetTypes = map[string]string{
+	"xterm.js":  "text/javascript; charset=utf-8",
+	"xterm.css": "text/css; charset=utf-8",
+}
+
+// pagePolicy keeps the viewer page from loading anything but its own files
+const pagePolicy = "default-src 'none'; script-src 'self' 'unsafe-inline'; " +
+	"style-src 'self' 'unsafe-inline'; connect-src 'self' ws: wss:"
+
+// Message is sent between the share and its viewers as JSON
+type Message struct {
+	Type  string `json:"type"`            // output, resize, mode or input
+	Data  string `json:"data,omitempty"`  // output or input
+	Rows  int    `json:"rows,omitempty"`  // resize
+	Cols  int    `json:"cols,omitempty"`  // resize
+	Input bool   `json:"input,omitempty"` // mode: viewers may type
+}
+
+// Viewer describes someone watching a share
+type Viewer struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Addr   string `json:"addr"`
+	Joined string `json:"joined"`
+}
+
+// Options configure a share
+type Options struct {
+	Addr       string // listen address, "127.0.0.1:0" if empty
+	AllowInput bool
+	Rows       int
+	Cols       int
+	History    []byte // output so far, replayed to viewers
+	// Input receives what viewers type while input is allowed
+	Input func(data string)
+	// Changed is called when viewers join or leave
+	Changed func()
+}
+
+// Share is a terminal being shared
+type Share struct {
+	token   string
+	addr    string
+	url     string
+	server  *http.Server
+	input   func(string)
+	changed func()
+
+	mu         sync.Mutex
+	history    []byte
+	rows, cols int
+	allowInput bool
+	viewers    map[int]*viewer
+	nextID     int
+	closed     bool
+}
+
+type viewer struct {
+	Viewer
+	conn *websocket.Conn
+	send chan Message
+	done chan struct{}
+	once sync.Once
+}
+
+func (v *viewer) close() {
+	v.once.Do(func() {
+		close(v.done)
+		v.conn.Close()
+	})
+}
+
+var upgrader = websocket.Upgrader{ReadBufferSize: 4096, WriteBufferSize: 32 * 1024}
+
+// Start listens on opts.Addr and serves the share until Close
+func Start(opts Options) (*Share, error) {
+	addr := opts.Addr
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Share{
+		token:      hex.EncodeToString(token),
+		input:      opts.Input,
+		changed:    opts.Changed,
+		rows:       opts.Rows,
+		cols:       opts.Cols,
+		allowInput: opts.AllowInput,
+		viewers:    make(map[int]*viewer),
+	}
+	s.appendHistory(opts.History)
+	s.addr = publicAddr(ln.Addr().(*net.TCPAddr))
+	s.url = fmt.Sprintf("http://%s/%s/", s.addr, s.token)
+
+	s.server = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go s.server.Serve(ln)
+	return s, nil
+}
+
+// publicAddr is the address to put in the URL. A wildcard listener is
+// reached through one of the machine's own addresses.
+func publicAddr(a *net.TCPAddr) string {
+	port := fmt.Sprint(a.Port)
+	if !a.IP.IsUnspecified() {
+		return net.JoinHostPort(a.IP.String(), port)
+	}
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+				return net.JoinHostPort(ipnet.IP.String(), port)
+			}
+		}
+	}
+	return net.JoinHostPort("localhost", port)
+}
+
+// URL is the address viewers open, token included
+func (s *Share) URL() string {
+	return s.url
+}
+
+// Addr is the host and port the share is reached on, without the token
+func (s *Share) Addr() string {
+	return s.addr
+}
+
+// AllowInput reports whether viewers may type
+func (s *Share) AllowInput() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.allowInput
+}
+
+// SetAllowInput lets viewers type or makes the share read-only again
+func (s *Share) SetAllowInput(allow bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.allowInput = allow
+	s.broadcast(Message{Type: "mode", Input: allow})
+}
+
+// Write sends terminal output to the viewers
+func (s *Share) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return len(p), nil
+	}
+	s.appendHistory(p)
+	s.broadcast(Message{Type: "output", Data: string(p)})
+	return len(p), nil
+}
+
+// Resize tells viewers the terminal's new size
+func (s *Share) Resize(rows, cols int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rows, s.cols = rows, cols
+	s.broadcast(Message{Type: "resize", Rows: rows, Cols: cols})
+}
+
+// Viewers lists who is watching, in the order they joined
+func (s *Share) Viewers() []Viewer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Viewer, 0, len(s.viewers))
+	for id := 0; id < s.nextID; id++ {
+		if v, ok := s.viewers[id]; ok {
+			list = append(list, v.Viewer)
+		}
+	}
+	return list
+}
+
+// Kick disconnects one viewer. They can rejoin with the same URL; Close
+// revokes it.
+func (s *Share) Kick(id int) bool {
+	s.mu.Lock()
+	v, ok := s.viewers[id]
+	s.mu.Unlock()
+	if ok {
+		v.close()
+	}
+	return ok
+}
+
+// Close revokes the share: the URL stops working and viewers are dropped
+func (s *Share) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	viewers := make([]*viewer, 0, len(s.viewers))
+	for _, v := range s.viewers {
+		viewers = append(viewers, v)
+	}
+	s.mu.Unlock()
+
+	err := s.server.Close()
+	for _, v := range viewers {
+		v.close()
+	}
+	return err
+}
+
+// appendHistory keeps the newest output for late joiners. Called with s.mu
+// held or before the share is running.
+func (s *Share) appendHistory(p []byte) {
+	s.history = append(s.history, p...)
+	if over := len(s.history) - HistorySize; over > 0 {
+		s.history = append(s.history[:0], s.history[over:]...)
+	}
+}
+
+// broadcast queues a message for every viewer, dropping those that can't
+// keep up. Called with s.mu held.
+func (s *Share) broadcast(m Message) {
+	for _, v := range s.viewers {
+		select {
+		case v.send <- m:
+		default:
+			go v.close()
+		}
+	}
+}
+
+func (s *Share) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch rest {
+	case "":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", pagePolicy)
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write(viewerPage)
+	case "ws":
+		s.serveViewer(w, r)
+	default:
+		typ, ok := assetTypes[rest]
+		data, err := assets.ReadFile("assets/" + rest)
+		if !ok || err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", typ)
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write(data)
+	}
+}
+
+// serveViewer streams the terminal to one viewer until either side leaves
+func (s *Share) serveViewer(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	if name == "" {
+		name = "Viewer"
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		conn.Close()
+		return
+	}
+	v := &viewer{
+		Viewer: Viewer{ID: s.nextID, Name: name, Addr: r.RemoteAddr, Joined: time.Now().Format(time.RFC3339)},
+		conn:   conn,
+		send:   make(chan Message, viewerQueue),
+		done:   make(chan struct{}),
+	}
+	s.nextID++
+	// Queued under the lock so nothing written meanwhile is missed or repeated
+	v.send <- Message{Type: "resize", Rows: s.rows, Cols: s.cols}
+	v.send <- Message{Type: "mode", Input: s.allowInput}
+	v.send <- Message{Type: "output", Data: string(s.history)}
+	s.viewers[v.ID] = v
+	s.mu.Unlock()
+	s.notify()
+
+	go s.writeLoop(v)
+	s.readLoop(v)
+
+	v.close()
+	s.mu.Lock()
+	delete(s.viewers, v.ID)
+	s.mu.Unlock()
+	s.notify()
+}
+
+func (s *Share) writeLoop(v *viewer) {
+	for {
+		select {
+		case m := <-v.send:
+			v.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := v.conn.WriteJSON(m); err != nil {
+				v.close()
+				return
+			}
+		case <-v.done:
+			return
+		}
+	}
+}
+
+// readLoop takes input from a viewer, passing it on only while allowed
+func (s *Share) readLoop(v *viewer) {
+	v.conn.SetReadLimit(maxInput + 256)
+	for {
+		_, data, err := v.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var m Message
+		if json.Unmarshal(data, &m) != nil || m.Type != "input" || len(m.Data) > maxInput {
+			continue
+		}
+		if s.AllowInput() && s.input != nil {
+			s.input(m.Data)
+		}
+	}
+}
+
+func (s *Share) notify() {
+	if s.changed != nil {
+		s.changed()
+	}
+}
@@ -0,0 +1,176 @@
+package share
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func dialViewer(t *testing.T, s *Share) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(s.URL(), "http") + "ws?name=alice"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// next reads messages until one of the given type arrives
+func next(t *testing.T, conn *websocket.Conn, typ string) Message {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var m Message
+		if err := conn.ReadJSON(&m); err != nil {
+			t.Fatalf("Waiting for %s: %v", typ, err)
+		}
+		if m.Type == typ {
+			return m
+		}
+	}
+}
+
+func TestShareToken(t *testing.T) {
+	s, err := Start(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	resp, err := http.Get(s.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(page), "WebSocket") {
+		t.Errorf("Viewer page: %d", resp.StatusCode)
+	}
+
+	wrong := strings.Replace(s.URL(), s.token, strings.Repeat("0", len(s.token)), 1)
+	resp, err = http.Get(wrong)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Wrong token got %d", resp.StatusCode)
+	}
+
+	if !strings.Contains(s.URL(), s.Addr()+"/") || strings.Contains(s.Addr(), s.token) {
+		t.Errorf("Addr %q of %q", s.Addr(), s.URL())
+	}
+}
+
+func TestShareServesOwnAssets(t *testing.T) {
+	s, err := Start(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	resp, err := http.Get(s.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if strings.Contains(string(page), "://") {
+		t.Error("Viewer page loads something from another site")
+	}
+	if !strings.Contains(resp.Header.Get("Content-Security-Policy"), "default-src 'none'") {
+		t.Errorf("Policy %q", resp.Header.Get("Content-Security-Policy"))
+	}
+
+	// Only the xterm files are served from the assets, and only with the token
+	for _, path := range []string{"README", "../share.go", "assets/README"} {
+		resp, err := http.Get(s.URL() + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s got %d", path, resp.StatusCode)
+		}
+	}
+	if _, err := assets.ReadFile("assets/xterm.js"); err == nil {
+		wrong := strings.Replace(s.URL(), s.token, strings.Repeat("0", len(s.token)), 1)
+		resp, err := http.Get(wrong + "xterm.js")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("xterm.js without the token got %d", resp.StatusCode)
+		}
+	}
+}
+
+func TestShareStream(t *testing.T) {
+	inputs := make(chan string, 4)
+	s, err := Start(Options{
+		Rows:    24,
+		Cols:    80,
+		History: []byte("$ uptime\r\n"),
+		Input:   func(data string) { inputs <- data },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	conn := dialViewer(t, s)
+	defer conn.Close()
+
+	if m := next(t, conn, "resize"); m.Rows != 24 || m.Cols != 80 {
+		t.Errorf("Size %dx%d", m.Cols, m.Rows)
+	}
+	if m := next(t, conn, "mode"); m.Input {
+		t.Error("Share not read-only by default")
+	}
+	if m := next(t, conn, "output"); m.Data != "$ uptime\r\n" {
+		t.Errorf("History %q", m.Data)
+	}
+
+	s.Write([]byte("up 3 days\r\n"))
+	if m := next(t, conn, "output"); m.Data != "up 3 days\r\n" {
+		t.Errorf("Live output %q", m.Data)
+	}
+	if v := s.Viewers(); len(v) != 1 || v[0].Name != "alice" {
+		t.Errorf("Viewers %+v", v)
+	}
+
+	// Ignored while read-only, passed on once allowed
+	conn.WriteJSON(Message{Type: "input", Data: "rm -rf /"})
+	select {
+	case got := <-inputs:
+		t.Fatalf("Read-only share passed on %q", got)
+	case <-time.After(200 * time.Millisecond):
+	}
+	s.SetAllowInput(true)
+	next(t, conn, "mode")
+	conn.WriteJSON(Message{Type: "input", Data: "ls\r"})
+	select {
+	case got := <-inputs:
+		if got != "ls\r" {
+			t.Errorf("Input %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Input not delivered")
+	}
+
+	// Revoking drops the viewer and the URL
+	s.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Error("Viewer still connected after revoke")
+	}
+	if _, err := http.Get(s.URL()); err == nil {
+		t.Error("Share still serving after revoke")
+	}
+}
@@ -1,6 +1,8 @@
 package ssh
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"net"
 	"os"
@@ -108,6 +110,35 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// Wait blocks until the connection closes, either through Close or
+// because the transport failed
+func (c *Client) Wait() error {
+	if c.client == nil {
+		return nil
+	}
+	return c.client.Wait()
+}
+
+// Ping checks that the server still answers on the connection
+func (c *Client) Ping(timeout time.Duration) error {
+	if c.client == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := c.client.SendRequest("keepalive@openssh.com", true, nil)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("no response from server after %s", timeout)
+	}
+}
+
 // GetClient returns the underlying ssh.Client
 func (c *Client) GetClient() *ssh.Client {
 	return c.client
@@ -128,6 +159,31 @@ func (c *Client) RunCommand(cmd string) (string, error) {
 	return string(output), nil
 }
 
+// ExecCommand runs a command on a new exec channel, streaming its output to
+// stdout and stderr. A non-zero exit is reported through the exit code, not err.
+func (c *Client) ExecCommand(cmd string, stdout, stderr io.Writer) (int, error) {
+	e, err := c.Start(context.Background(), ExecOptions{Command: cmd})
+	if err != nil {
+		return -1, err
+	}
+	defer e.Close()
+	e.Stdin.Close()
+
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(stderr, e.Stderr)
+		close(copied)
+	}()
+	io.Copy(stdout, e.Stdout)
+	<-copied
+
+	status, err := e.Wait()
+	if err != nil {
+		return -1, err
+	}
+	return status.Code, nil
+}
+
 // PrepareShell creates a session and requests a PTY, but doesn't start the shell or assign pipes yet.
 func (c *Client) PrepareShell(width, height int) (*ssh.Session, error) {
 	session, err := c.client.NewSession()
@@ -0,0 +1,177 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// killGrace is how long a cancelled command gets to exit after its signal
+// before the channel is closed under it
+var killGrace = 3 * time.Second
+
+// ExecOptions configures a command started with Start
+type ExecOptions struct {
+	Command string
+	PTY     bool
+	Rows    int
+	Cols    int
+	Env     map[string]string // servers often only accept names in AcceptEnv
+	Signal  ssh.Signal        // sent on context cancellation, defaults to TERM
+}
+
+// ExitStatus describes how a remote command ended
+type ExitStatus struct {
+	Code    int    `json:"code"`             // -1 if the server reported none
+	Signal  string `json:"signal,omitempty"` // set when killed by a signal
+	Message string `json:"message,omitempty"`
+}
+
+// Exec is a command running on its own exec channel. Stdout and Stderr
+// must both be drained or the remote side will stall.
+type Exec struct {
+	Stdout io.Reader
+	Stderr io.Reader
+	Stdin  io.WriteCloser
+
+	session *ssh.Session
+	done    chan struct{}
+	status  ExitStatus
+	err     error
+}
+
+// Start runs a command with separate output streams. Cancelling ctx sends
+// the configured signal and closes the channel if the command lingers.
+func (c *Client) Start(ctx context.Context, opts ExecOptions) (*Exec, error) {
+	if c.client == nil {
+		return nil, fmt.Errorf("ssh client not connected")
+	}
+
+	session, err := c.client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range opts.Env {
+		// Rejected variables aren't fatal, the command still runs
+		_ = session.Setenv(name, value)
+	}
+
+	if opts.PTY {
+		rows, cols := opts.Rows, opts.Cols
+		if rows <= 0 || cols <= 0 {
+			rows, cols = 24, 80
+		}
+		modes := ssh.TerminalModes{
+			ssh.ECHO:          0,
+			ssh.TTY_OP_ISPEED: 14400,
+			ssh.TTY_OP_OSPEED: 14400,
+		}
+		if err := session.RequestPty("xterm", rows, cols, modes); err != nil {
+			session.Close()
+			return nil, err
+		}
+	}
+
+	e := &Exec{
+		session: session,
+		done:    make(chan struct{}),
+	}
+	if e.Stdout, err = session.StdoutPipe(); err != nil {
+		session.Close()
+		return nil, err
+	}
+	if e.Stderr, err = session.StderrPipe(); err != nil {
+		session.Close()
+		return nil, err
+	}
+	if e.Stdin, err = session.StdinPipe(); err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	if err := session.Start(opts.Command); err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	go func() {
+		e.status, e.err = exitStatus(session.Wait())
+		close(e.done)
+	}()
+
+	sig := opts.Signal
+	if sig == "" {
+		sig = ssh.SIGTERM
+	}
+	grace := killGrace
+	go func() {
+		select {
+		case <-e.done:
+			return
+		case <-ctx.Done():
+		}
+
+		_ = session.Signal(sig)
+		select {
+		case <-e.done:
+		case <-time.After(grace):
+			session.Close()
+		}
+	}()
+
+	return e, nil
+}
+
+// SessionExitStatus interprets the error from ssh.Session.Wait the same way
+// Exec.Wait does, for shells started outside Start
+func SessionExitStatus(err error) (ExitStatus, error) {
+	return exitStatus(err)
+}
+
+func exitStatus(err error) (ExitStatus, error) {
+	if err == nil {
+		return ExitStatus{Code: 0}, nil
+	}
+
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return ExitStatus{
+			Code:    exitErr.ExitStatus(),
+			Signal:  exitErr.Signal(),
+			Message: exitErr.Msg(),
+		}, nil
+	}
+	return ExitStatus{Code: -1}, err
+}
+
+// Wait blocks until the command exits. err is only set when the channel
+// closed without an exit status, e.g. because the connection dropped.
+func (e *Exec) Wait() (ExitStatus, error) {
+	<-e.done
+	return e.status, e.err
+}
+
+// Done is closed once the command has exited
+func (e *Exec) Done() <-chan struct{} {
+	return e.done
+}
+
+// Signal delivers a signal such as "INT" or "KILL" to the remote command
+func (e *Exec) Signal(sig ssh.Signal) error {
+	return e.session.Signal(sig)
+}
+
+// Resize changes the PTY size of a command started with PTY
+func (e *Exec) Resize(rows, cols int) error {
+	return e.session.WindowChange(rows, cols)
+}
+
+// Close closes the channel, abandoning the command
+func (e *Exec) Close() error {
+	return e.session.Close()
+}
@@ -0,0 +1,274 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// testClient connects to an in-process SSH server. Its commands are
+// scripted rather than run:
+//
+//	exit N    prints "out" and "err" and exits with N
+//	env NAME  prints the value the client set for NAME
+//	tty       prints the PTY size as COLSxROWS, or "none"
+//	wait      exits by the first signal it receives
+//	ignore    ignores signals and never exits
+func testClient(t *testing.T) *Client {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(ssh.ConnMetadata, []byte) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for nc := range chans {
+					ch, creqs, err := nc.Accept()
+					if err != nil {
+						continue
+					}
+					go serveSession(ch, creqs)
+				}
+			}()
+		}
+	}()
+
+	c, err := NewClient("user", "secret", "127.0.0.1", 0, 5*time.Second, ssh.InsecureIgnoreHostKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Connect(ln.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func serveSession(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	env := make(map[string]string)
+	pty := "none"
+	signals := make(chan string, 4)
+	for req := range reqs {
+		switch req.Type {
+		case "env":
+			var kv struct{ Name, Value string }
+			ssh.Unmarshal(req.Payload, &kv)
+			env[kv.Name] = kv.Value
+			req.Reply(true, nil)
+		case "pty-req":
+			var p struct {
+				Term             string
+				Cols, Rows, W, H uint32
+				Modes            string
+			}
+			ssh.Unmarshal(req.Payload, &p)
+			pty = fmt.Sprintf("%dx%d", p.Cols, p.Rows)
+			req.Reply(true, nil)
+		case "exec":
+			var e struct{ Command string }
+			ssh.Unmarshal(req.Payload, &e)
+			req.Reply(true, nil)
+			go runScripted(ch, e.Command, env[strings.TrimPrefix(e.Command, "env ")], pty, signals)
+		case "signal":
+			var s struct{ Signal string }
+			ssh.Unmarshal(req.Payload, &s)
+			select {
+			case signals <- s.Signal:
+			default:
+			}
+		default:
+			if req.WantReply {
+				req.Reply(false, nil)
+			}
+		}
+	}
+}
+
+func runScripted(ch ssh.Channel, command, env, pty string, signals <-chan string) {
+	defer ch.Close()
+
+	name, arg, _ := strings.Cut(command, " ")
+	switch name {
+	case "exit":
+		code, _ := strconv.Atoi(arg)
+		io.WriteString(ch, "out\n")
+		io.WriteString(ch.Stderr(), "err\n")
+		sendExit(ch, code)
+	case "env":
+		io.WriteString(ch, env)
+		sendExit(ch, 0)
+	case "tty":
+		io.WriteString(ch, pty)
+		sendExit(ch, 0)
+	case "wait":
+		sig := <-signals
+		ch.SendRequest("exit-signal", false, ssh.Marshal(struct {
+			Signal     string
+			CoreDumped bool
+			Error      string
+			Lang       string
+		}{sig, false, "", ""}))
+	case "ignore":
+		// Only the client closing the channel ends this
+		io.Copy(io.Discard, ch)
+	}
+}
+
+func sendExit(ch ssh.Channel, code int) {
+	ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(code)}))
+}
+
+// output runs a command and returns what it printed
+func output(t *testing.T, c *Client, opts ExecOptions) string {
+	t.Helper()
+	e, err := c.Start(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	go io.Copy(io.Discard, e.Stderr)
+	out, _ := io.ReadAll(e.Stdout)
+	if _, err := e.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestExecCommand(t *testing.T) {
+	c := testClient(t)
+	for _, want := range []int{0, 3} {
+		var stdout, stderr bytes.Buffer
+		code, err := c.ExecCommand(fmt.Sprintf("exit %d", want), &stdout, &stderr)
+		if err != nil || code != want {
+			t.Errorf("ExecCommand = %d, %v; want %d", code, err, want)
+		}
+		if stdout.String() != "out\n" || stderr.String() != "err\n" {
+			t.Errorf("Streams %q, %q", stdout.String(), stderr.String())
+		}
+	}
+}
+
+func TestStartOptions(t *testing.T) {
+	c := testClient(t)
+	if got := output(t, c, ExecOptions{Command: "env LANG", Env: map[string]string{"LANG": "C.UTF-8"}}); got != "C.UTF-8" {
+		t.Errorf("Env gave %q", got)
+	}
+	if got := output(t, c, ExecOptions{Command: "tty", PTY: true, Rows: 30, Cols: 100}); got != "100x30" {
+		t.Errorf("PTY gave %q", got)
+	}
+	if got := output(t, c, ExecOptions{Command: "tty", PTY: true}); got != "80x24" {
+		t.Errorf("Default PTY gave %q", got)
+	}
+	if got := output(t, c, ExecOptions{Command: "tty"}); got != "none" {
+		t.Errorf("No PTY gave %q", got)
+	}
+}
+
+// waitExit waits for e to end within a few seconds
+func waitExit(t *testing.T, e *Exec) (ExitStatus, error) {
+	t.Helper()
+	go io.Copy(io.Discard, e.Stdout)
+	go io.Copy(io.Discard, e.Stderr)
+	select {
+	case <-e.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Command didn't end")
+	}
+	return e.Wait()
+}
+
+func TestExecSignals(t *testing.T) {
+	c := testClient(t)
+
+	e, err := c.Start(context.Background(), ExecOptions{Command: "wait"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Signal(ssh.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+	status, err := waitExit(t, e)
+	if err != nil || status.Signal != "INT" || status.Code == 0 {
+		t.Errorf("After INT: %+v, %v", status, err)
+	}
+
+	// Cancelling sends the configured signal, TERM by default
+	for sig, want := range map[ssh.Signal]string{"": "TERM", ssh.SIGHUP: "HUP"} {
+		ctx, cancel := context.WithCancel(context.Background())
+		e, err := c.Start(ctx, ExecOptions{Command: "wait", Signal: sig})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		status, err := waitExit(t, e)
+		if err != nil || status.Signal != want {
+			t.Errorf("Cancelled with %q: %+v, %v", sig, status, err)
+		}
+	}
+}
+
+func TestStartKillGrace(t *testing.T) {
+	defer func(d time.Duration) { killGrace = d }(killGrace)
+	killGrace = 50 * time.Millisecond
+
+	c := testClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	e, err := c.Start(ctx, ExecOptions{Command: "ignore"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	// The command ignores its signal, so the channel is closed under it
+	// and no exit status arrives
+	status, err := waitExit(t, e)
+	if err == nil || status.Code != -1 {
+		t.Errorf("Lingering command: %+v, %v", status, err)
+	}
+}
+
+func TestSessionExitStatus(t *testing.T) {
+	if status, err := SessionExitStatus(nil); err != nil || status.Code != 0 {
+		t.Errorf("Clean exit: %+v, %v", status, err)
+	}
+	missing := &ssh.ExitMissingError{}
+	if status, err := SessionExitStatus(missing); err != missing || status.Code != -1 {
+		t.Errorf("Missing status: %+v, %v", status, err)
+	}
+}
@@ -0,0 +1,342 @@
+// Package telnet is a telnet client for terminal sessions. It negotiates
+// window size (NAWS), terminal type, echo and suppress-go-ahead and passes
+// everything else through as terminal data.
+package telnet
+
+import (
+	"bytes"
+	"net"
+	"sync"
+	"time"
+)
+
+// Telnet commands
+const (
+	cmdSE   = 240
+	cmdSB   = 250
+	cmdWILL = 251
+	cmdWONT = 252
+	cmdDO   = 253
+	cmdDONT = 254
+	cmdIAC  = 255
+)
+
+// Telnet options
+const (
+	optBinary = 0
+	optEcho   = 1
+	optSGA    = 3
+	optTType  = 24
+	optNAWS   = 31
+)
+
+// Terminal type subnegotiation commands
+const (
+	ttypeIS   = 0
+	ttypeSEND = 1
+)
+
+// maxSubneg bounds the subnegotiation data kept from the server
+const maxSubneg = 256
+
+// Options configure a telnet connection
+type Options struct {
+	TermType string // reported when the server asks, "xterm-256color" if empty
+	Rows     int
+	Cols     int
+}
+
+// option tracks one option for each side, after RFC 1143: whether it is
+// on and whether we asked for it and wait for the answer
+type option struct {
+	us, usAsked   bool // we perform the option
+	him, himAsked bool // the server performs the option
+}
+
+// Conn is a telnet connection. Reads return terminal data with telnet
+// commands removed; writes send terminal input, escaped as needed.
+type Conn struct {
+	conn     net.Conn
+	termType string
+
+	mu      sync.Mutex // guards writes and the negotiation state
+	opts    [256]option
+	rows    int
+	cols    int
+	state   parseState
+	cmd     byte
+	subneg  []byte
+	lastCR  bool // the previous data byte was CR, so a NUL after it is padding
+	readBuf []byte
+}
+
+type parseState int
+
+const (
+	stateData parseState = iota
+	stateIAC
+	stateOption // after WILL, WONT, DO or DONT
+	stateSub    // inside SB ... IAC SE
+	stateSubIAC
+)
+
+// Dial connects to a telnet server and starts negotiating
+func Dial(addr string, opts Options, timeout time.Duration) (*Conn, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewConn(conn, opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewConn runs telnet over an established connection. It offers window
+// size and terminal type and asks the server to echo and suppress go-ahead.
+func NewConn(conn net.Conn, opts Options) (*Conn, error) {
+	c := &Conn{conn: conn, termType: opts.TermType, rows: opts.Rows, cols: opts.Cols}
+	if c.termType == "" {
+		c.termType = "xterm-256color"
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []byte
+	out = c.ask(out, cmdWILL, optNAWS)
+	out = c.ask(out, cmdWILL, optTType)
+	out = c.ask(out, cmdDO, optEcho)
+	out = c.ask(out, cmdDO, optSGA)
+	if _, err := c.conn.Write(out); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Read returns terminal data from the server, answering telnet commands
+// found along the way
+func (c *Conn) Read(p []byte) (int, error) {
+	if len(c.readBuf) < len(p) {
+		c.readBuf = make([]byte, len(p))
+	}
+	for {
+		n, err := c.conn.Read(c.readBuf[:len(p)])
+		if n > 0 {
+			data, reply := c.parse(c.readBuf[:n], p[:0])
+			if len(reply) > 0 {
+				if werr := c.write(reply); werr != nil && err == nil {
+					err = werr
+				}
+			}
+			if len(data) > 0 || err != nil {
+				return len(data), err
+			}
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Write sends terminal input. IAC bytes are doubled and a lone CR is sent
+// as CR NUL, as the protocol requires outside binary mode.
+func (c *Conn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	binary := c.opts[optBinary].us
+	c.mu.Unlock()
+
+	out := make([]byte, 0, len(p)+8)
+	for i, b := range p {
+		switch {
+		case b == cmdIAC:
+			out = append(out, cmdIAC, cmdIAC)
+		case b == '\r' && !binary && (i+1 >= len(p) || p[i+1] != '\n'):
+			out = append(out, '\r', 0)
+		default:
+			out = append(out, b)
+		}
+	}
+	if err := c.write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WindowChange reports a new terminal size if the server asked for it
+func (c *Conn) WindowChange(rows, cols int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rows, c.cols = rows, cols
+	if !c.opts[optNAWS].us {
+		return nil
+	}
+	_, err := c.conn.Write(c.naws(nil))
+	return err
+}
+
+// Close closes the connection
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Conn) write(p []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.conn.Write(p)
+	return err
+}
+
+// ask requests an option and remembers the request so the answer isn't
+// answered again. Called with c.mu held.
+func (c *Conn) ask(out []byte, cmd, opt byte) []byte {
+	o := &c.opts[opt]
+	if cmd == cmdWILL {
+		o.usAsked = true
+	} else {
+		o.himAsked = true
+	}
+	return append(out, cmdIAC, cmd, opt)
+}
+
+// parse splits server output into terminal data, appended to data, and
+// the replies owed to the server
+func (c *Conn) parse(p, data []byte) ([]byte, []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var reply []byte
+	for _, b := range p {
+		switch c.state {
+		case stateData:
+			if b == cmdIAC {
+				c.state = stateIAC
+				continue
+			}
+			if b == 0 && c.lastCR && !c.opts[optBinary].him {
+				// CR NUL is a bare carriage return
+				c.lastCR = false
+				continue
+			}
+			c.lastCR = b == '\r'
+			data = append(data, b)
+
+		case stateIAC:
+			c.state = stateData
+			switch b {
+			case cmdIAC:
+				c.lastCR = false
+				data = append(data, cmdIAC)
+			case cmdWILL, cmdWONT, cmdDO, cmdDONT:
+				c.cmd = b
+				c.state = stateOption
+			case cmdSB:
+				c.subneg = c.subneg[:0]
+				c.state = stateSub
+			}
+			// NOP, GA and the rest carry nothing for a terminal
+
+		case stateOption:
+			c.state = stateData
+			reply = c.negotiate(reply, c.cmd, b)
+
+		case stateSub:
+			if b == cmdIAC {
+				c.state = stateSubIAC
+			} else if len(c.subneg) < maxSubneg {
+				c.subneg = append(c.subneg, b)
+			}
+
+		case stateSubIAC:
+			switch b {
+			case cmdSE:
+				c.state = stateData
+				reply = c.subnegotiate(reply, c.subneg)
+			case cmdIAC:
+				c.state = stateSub
+				if len(c.subneg) < maxSubneg {
+					c.subneg = append(c.subneg, cmdIAC)
+				}
+			default:
+				// Malformed; drop the subnegotiation
+				c.state = stateData
+			}
+		}
+	}
+	return data, reply
+}
+
+// negotiate answers WILL, WONT, DO and DONT for an option
+func (c *Conn) negotiate(reply []byte, cmd, opt byte) []byte {
+	o := &c.opts[opt]
+	switch cmd {
+	case cmdWILL:
+		if o.him {
+			break
+		}
+		if opt == optEcho || opt == optSGA || opt == optBinary {
+			o.him = true
+			if !o.himAsked {
+				reply = append(reply, cmdIAC, cmdDO, opt)
+			}
+		} else if !o.himAsked {
+			reply = append(reply, cmdIAC, cmdDONT, opt)
+		}
+		o.himAsked = false
+
+	case cmdWONT:
+		if o.him || o.himAsked {
+			if !o.himAsked {
+				reply = append(reply, cmdIAC, cmdDONT, opt)
+			}
+			o.him, o.himAsked = false, false
+		}
+
+	case cmdDO:
+		if o.us {
+			break
+		}
+		if opt == optNAWS || opt == optTType || opt == optSGA || opt == optBinary {
+			o.us = true
+			if !o.usAsked {
+				reply = append(reply, cmdIAC, cmdWILL, opt)
+			}
+			if opt == optNAWS {
+				reply = c.naws(reply)
+			}
+		} else if !o.usAsked {
+			reply = append(reply, cmdIAC, cmdWONT, opt)
+		}
+		o.usAsked = false
+
+	case cmdDONT:
+		if o.us || o.usAsked {
+			if !o.usAsked {
+				reply = append(reply, cmdIAC, cmdWONT, opt)
+			}
+			o.us, o.usAsked = false, false
+		}
+	}
+	return reply
+}
+
+// subnegotiate answers a subnegotiation; only terminal type needs one
+func (c *Conn) subnegotiate(reply, sub []byte) []byte {
+	if len(sub) >= 2 && sub[0] == optTType && sub[1] == ttypeSEND && c.opts[optTType].us {
+		reply = append(reply, cmdIAC, cmdSB, optTType, ttypeIS)
+		reply = append(reply, c.termType...)
+		reply = append(reply, cmdIAC, cmdSE)
+	}
+	return reply
+}
+
+// naws appends the window size subnegotiation
+func (c *Conn) naws(out []byte) []byte {
+	size := []byte{byte(c.cols >> 8), byte(c.cols), byte(c.rows >> 8), byte(c.rows)}
+	out = append(out, cmdIAC, cmdSB, optNAWS)
+	out = append(out, bytes.ReplaceAll(size, []byte{cmdIAC}, []byte{cmdIAC, cmdIAC})...)
+	return append(out, cmdIAC, cmdSE)
+}
@@ -0,0 +1,110 @@
+package telnet
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// server is a stand-in telnet server reading what the client sends
+type server struct {
+	t    *testing.T
+	conn net.Conn
+}
+
+func (s *server) send(p ...byte) {
+	s.t.Helper()
+	if _, err := s.conn.Write(p); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+// expect reads until want has arrived and fails on anything else first
+func (s *server) expect(want ...byte) {
+	s.t.Helper()
+	got := make([]byte, len(want))
+	s.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.ReadFull(s.conn, got); err != nil {
+		s.t.Fatalf("Waiting for % x: %v (got % x)", want, err, got)
+	}
+	if !bytes.Equal(got, want) {
+		s.t.Fatalf("Got % x, want % x", got, want)
+	}
+}
+
+func TestNegotiation(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := ln.Accept()
+		accepted <- conn
+	}()
+
+	c, err := Dial(ln.Addr().String(), Options{Rows: 24, Cols: 80}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	s := &server{t: t, conn: <-accepted}
+	defer s.conn.Close()
+
+	// The client opens with its own requests
+	s.expect(cmdIAC, cmdWILL, optNAWS, cmdIAC, cmdWILL, optTType, cmdIAC, cmdDO, optEcho, cmdIAC, cmdDO, optSGA)
+
+	received := make(chan []byte, 16)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := c.Read(buf)
+			if n > 0 {
+				received <- append([]byte(nil), buf[:n]...)
+			}
+			if err != nil {
+				close(received)
+				return
+			}
+		}
+	}()
+
+	// Answers to the client's requests need no reply, except the window size
+	s.send(cmdIAC, cmdDO, optNAWS, cmdIAC, cmdDO, optTType, cmdIAC, cmdWILL, optEcho, cmdIAC, cmdWILL, optSGA)
+	s.expect(cmdIAC, cmdSB, optNAWS, 0, 80, 0, 24, cmdIAC, cmdSE)
+
+	// Unknown options are refused
+	s.send(cmdIAC, cmdDO, 39, cmdIAC, cmdWILL, 5)
+	s.expect(cmdIAC, cmdWONT, 39, cmdIAC, cmdDONT, 5)
+
+	s.send(cmdIAC, cmdSB, optTType, ttypeSEND, cmdIAC, cmdSE)
+	s.expect(append(append([]byte{cmdIAC, cmdSB, optTType, ttypeIS}, "xterm-256color"...), cmdIAC, cmdSE)...)
+
+	// A width of 255 is escaped in the subnegotiation
+	c.WindowChange(30, 255)
+	s.expect(cmdIAC, cmdSB, optNAWS, 0, 255, 255, 0, 30, cmdIAC, cmdSE)
+
+	// Input: IAC doubled, a lone CR padded with NUL
+	c.Write([]byte("a\xffb\r"))
+	s.expect('a', cmdIAC, cmdIAC, 'b', '\r', 0)
+
+	// Output: commands stripped, IAC IAC and CR NUL decoded
+	s.send([]byte("login:\xff\xff \r\x00\r\n\xff\xf1ok")...)
+	var out []byte
+	deadline := time.After(2 * time.Second)
+	for !bytes.HasSuffix(out, []byte("ok")) {
+		select {
+		case p := <-received:
+			out = append(out, p...)
+		case <-deadline:
+			t.Fatalf("Output so far %q", out)
+		}
+	}
+	if want := "login:\xff \r\r\nok"; string(out) != want {
+		t.Errorf("Output %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,76 @@
+package terminal
+
+// Stripper states
+const (
+	stateGround = iota
+	stateEscape
+	stateEscInter
+	stateCSI
+	stateString
+	stateStringEsc
+)
+
+// Stripper removes ANSI escape sequences from a byte stream. It keeps its
+// parser state between calls so sequences split across reads are handled.
+type Stripper struct {
+	state int
+}
+
+// Strip returns p with escape sequences and non-printing control characters removed
+func (s *Stripper) Strip(p []byte) []byte {
+	out := make([]byte, 0, len(p))
+	for _, b := range p {
+		switch s.state {
+		case stateGround:
+			switch {
+			case b == 0x1b:
+				s.state = stateEscape
+			case b == '\n' || b == '\r' || b == '\t' || b == '\b':
+				out = append(out, b)
+			case b < 0x20 || b == 0x7f:
+				// drop BEL, SI/SO and friends
+			default:
+				out = append(out, b)
+			}
+		case stateEscape:
+			switch {
+			case b == '[':
+				s.state = stateCSI
+			case b == ']' || b == 'P' || b == 'X' || b == '^' || b == '_':
+				s.state = stateString
+			case b >= 0x20 && b <= 0x2f:
+				s.state = stateEscInter
+			default:
+				s.state = stateGround
+			}
+		case stateEscInter:
+			if b < 0x20 || b > 0x2f {
+				s.state = stateGround
+			}
+		case stateCSI:
+			if b >= 0x40 && b <= 0x7e {
+				s.state = stateGround
+			}
+		case stateString:
+			switch b {
+			case 0x07:
+				s.state = stateGround
+			case 0x1b:
+				s.state = stateStringEsc
+			}
+		case stateStringEsc:
+			if b == '\\' {
+				s.state = stateGround
+			} else {
+				s.state = stateString
+			}
+		}
+	}
+	return out
+}
+
+// StripANSI removes escape sequences from a complete string
+func StripANSI(s string) string {
+	var st Stripper
+	return string(st.Strip([]byte(s)))
+}
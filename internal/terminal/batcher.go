@@ -0,0 +1,238 @@
+package terminal
+
+import (
+	"sync"
+	"time"
+)
+
+// BatcherOptions tune how output is grouped into frames
+type BatcherOptions struct {
+	Interval   time.Duration // how long to gather output before sending a frame
+	MaxFrame   int           // send at once when this many bytes are waiting
+	MaxPending int           // Write blocks while this many bytes are waiting
+	Window     int           // frames sent but not acknowledged before pausing, 0 disables
+	AckTimeout time.Duration // give up waiting for acknowledgements after this long
+}
+
+// DefaultBatcherOptions suit an xterm.js view behind the Wails event bridge
+var DefaultBatcherOptions = BatcherOptions{
+	Interval:   16 * time.Millisecond,
+	MaxFrame:   64 * 1024,
+	MaxPending: 256 * 1024,
+	Window:     8,
+	AckTimeout: 2 * time.Second,
+}
+
+// Batcher coalesces terminal output into frames sent on a short timer or
+// once enough has piled up. Frames always end on a UTF-8 character
+// boundary. When the receiver falls behind acknowledging frames, Write
+// blocks, which pushes back on the SSH channel instead of queuing without bound.
+type Batcher struct {
+	opts BatcherOptions
+	emit func(frame string)
+
+	mu       sync.Mutex
+	space    *sync.Cond // signalled when pending shrinks
+	pending  []byte     // complete characters waiting to be sent
+	tail     []byte     // start of a character split across writes
+	inFlight int        // frames sent but not acknowledged
+	closed   bool
+
+	kick  chan struct{} // output arrived
+	full  chan struct{} // a whole frame is waiting
+	acked chan struct{}
+	done  chan struct{}
+	idle  chan struct{} // closed when the send loop exits
+}
+
+// NewBatcher starts a batcher that passes frames to emit from its own goroutine
+func NewBatcher(opts BatcherOptions, emit func(frame string)) *Batcher {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultBatcherOptions.Interval
+	}
+	if opts.MaxFrame <= 0 {
+		opts.MaxFrame = DefaultBatcherOptions.MaxFrame
+	}
+	if opts.MaxPending < opts.MaxFrame {
+		opts.MaxPending = opts.MaxFrame
+	}
+	if opts.AckTimeout <= 0 {
+		opts.AckTimeout = DefaultBatcherOptions.AckTimeout
+	}
+
+	b := &Batcher{
+		opts:  opts,
+		emit:  emit,
+		kick:  make(chan struct{}, 1),
+		full:  make(chan struct{}, 1),
+		acked: make(chan struct{}, 1),
+		done:  make(chan struct{}),
+		idle:  make(chan struct{}),
+	}
+	b.space = sync.NewCond(&b.mu)
+	go b.run()
+	return b
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// Write queues output, blocking while too much is waiting to be sent
+func (b *Batcher) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for !b.closed && len(b.pending) >= b.opts.MaxPending {
+		b.space.Wait()
+	}
+	if b.closed {
+		return len(p), nil
+	}
+
+	var complete []byte
+	complete, b.tail = SplitIncompleteUTF8(append(b.tail, p...))
+	b.pending = append(b.pending, complete...)
+	b.tail = append([]byte(nil), b.tail...)
+
+	signal(b.kick)
+	if len(b.pending) >= b.opts.MaxFrame {
+		signal(b.full)
+	}
+	return len(p), nil
+}
+
+// Ack tells the batcher the receiver has processed a frame
+func (b *Batcher) Ack() {
+	b.mu.Lock()
+	if b.inFlight > 0 {
+		b.inFlight--
+	}
+	b.mu.Unlock()
+	signal(b.acked)
+}
+
+// Close sends whatever is left and stops the batcher. Writes after Close are dropped.
+func (b *Batcher) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	b.space.Broadcast()
+	b.mu.Unlock()
+
+	close(b.done)
+	<-b.idle
+}
+
+// take removes up to one frame of output, cut at a character boundary.
+// Only a character split by the cut is held back, so invalid output still
+// moves along; the frame is empty only when nothing is pending.
+func (b *Batcher) take() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(b.pending)
+	if n == 0 {
+		return ""
+	}
+	if n > b.opts.MaxFrame {
+		head, _ := SplitIncompleteUTF8(b.pending[:b.opts.MaxFrame])
+		n = len(head)
+		if n == 0 {
+			// A frame too small for one character
+			n = b.opts.MaxFrame
+		}
+	}
+	frame := string(b.pending[:n])
+	b.pending = append(b.pending[:0], b.pending[n:]...)
+	b.inFlight++
+	b.space.Broadcast()
+	return frame
+}
+
+func (b *Batcher) hasPending() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending) > 0
+}
+
+func (b *Batcher) windowFull() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.opts.Window > 0 && b.inFlight >= b.opts.Window
+}
+
+func (b *Batcher) run() {
+	defer close(b.idle)
+
+	timer := time.NewTimer(0)
+	<-timer.C
+
+	for {
+		select {
+		case <-b.kick:
+		case <-b.done:
+			b.flush()
+			return
+		}
+
+		// Gather output for one interval unless a full frame is ready
+		timer.Reset(b.opts.Interval)
+		select {
+		case <-timer.C:
+		case <-b.full:
+			if !timer.Stop() {
+				<-timer.C
+			}
+		case <-b.done:
+			timer.Stop()
+			b.flush()
+			return
+		}
+
+		for b.hasPending() {
+			// Hold frames back while the receiver is behind. A receiver that
+			// stopped acknowledging altogether is given up on after a while.
+			for b.windowFull() {
+				timer.Reset(b.opts.AckTimeout)
+				select {
+				case <-b.acked:
+					if !timer.Stop() {
+						<-timer.C
+					}
+				case <-timer.C:
+					b.mu.Lock()
+					b.inFlight = 0
+					b.mu.Unlock()
+				case <-b.done:
+					timer.Stop()
+					b.flush()
+					return
+				}
+			}
+			if frame := b.take(); frame != "" {
+				b.emit(frame)
+			}
+		}
+	}
+}
+
+// flush sends everything that is left, including a dangling partial character
+func (b *Batcher) flush() {
+	b.mu.Lock()
+	b.pending = append(b.pending, b.tail...)
+	b.tail = nil
+	b.mu.Unlock()
+
+	for b.hasPending() {
+		if frame := b.take(); frame != "" {
+			b.emit(frame)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package terminal
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestBatcherUTF8Frames(t *testing.T) {
+	var mu sync.Mutex
+	var frames []string
+	b := NewBatcher(BatcherOptions{Interval: time.Millisecond, MaxFrame: 5}, func(frame string) {
+		mu.Lock()
+		frames = append(frames, frame)
+		mu.Unlock()
+	})
+
+	// "€" and "✓" are three bytes each and are split across writes
+	input := []byte("price: 5€ ✓ ok")
+	for i := 0; i < len(input); i += 2 {
+		end := min(i+2, len(input))
+		b.Write(input[i:end])
+	}
+	b.Close()
+
+	for _, f := range frames {
+		if !utf8.ValidString(f) {
+			t.Errorf("Frame %q is not valid UTF-8", f)
+		}
+	}
+	if got := strings.Join(frames, ""); got != string(input) {
+		t.Errorf("Frames joined to %q", got)
+	}
+}
+
+func TestBatcherBackpressure(t *testing.T) {
+	frames := make(chan string, 10)
+	b := NewBatcher(BatcherOptions{
+		Interval:   time.Millisecond,
+		MaxFrame:   4,
+		MaxPending: 4,
+		Window:     1,
+		AckTimeout: time.Hour,
+	}, func(frame string) { frames <- frame })
+	defer b.Close()
+
+	b.Write([]byte("abcd"))
+	if f := <-frames; f != "abcd" {
+		t.Fatalf("Unexpected first frame %q", f)
+	}
+
+	// The first frame is unacknowledged, so the next one waits and a
+	// third write has nowhere to go
+	b.Write([]byte("efgh"))
+	written := make(chan struct{})
+	go func() {
+		b.Write([]byte("ijkl"))
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("Write did not block while the receiver was behind")
+	case f := <-frames:
+		t.Fatalf("Frame %q sent before acknowledgement", f)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	b.Ack()
+	if f := <-frames; f != "efgh" {
+		t.Fatalf("Unexpected second frame %q", f)
+	}
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("Write still blocked after acknowledgement")
+	}
+}
+
+// heavyOutput stands in for a command like `cat` on a large file, arriving
+// in the small reads an SSH channel typically delivers
+func heavyOutput() [][]byte {
+	line := []byte("drwxr-xr-x  2 root root 4096 Oct 18 12:00 übersicht ✓\r\n")
+	data := bytes.Repeat(line, (1<<20)/len(line))
+	var chunks [][]byte
+	for len(data) > 0 {
+		n := min(4096, len(data))
+		chunks = append(chunks, data[:n])
+		data = data[n:]
+	}
+	return chunks
+}
+
+// frameStats counts frames and measures how long each chunk waited between
+// being written and being part of a sent frame
+type frameStats struct {
+	mu      sync.Mutex
+	events  int
+	sent    int
+	pending []chunkMark
+	latency time.Duration
+	chunks  int
+}
+
+type chunkMark struct {
+	end int
+	at  time.Time
+}
+
+func (s *frameStats) wrote(total int) {
+	s.mu.Lock()
+	s.pending = append(s.pending, chunkMark{total, time.Now()})
+	s.mu.Unlock()
+}
+
+func (s *frameStats) emitted(n int) {
+	now := time.Now()
+	s.mu.Lock()
+	s.events++
+	s.sent += n
+	for len(s.pending) > 0 && s.pending[0].end <= s.sent {
+		s.latency += now.Sub(s.pending[0].at)
+		s.chunks++
+		s.pending = s.pending[1:]
+	}
+	s.mu.Unlock()
+}
+
+func (s *frameStats) report(b *testing.B) {
+	b.ReportMetric(float64(s.events)/float64(b.N), "events/op")
+	if s.chunks > 0 {
+		b.ReportMetric(float64(s.latency.Microseconds())/float64(s.chunks), "µs-latency")
+	}
+}
+
+// BenchmarkUnbatchedOutput is the baseline of one event per read
+func BenchmarkUnbatchedOutput(b *testing.B) {
+	chunks := heavyOutput()
+	stats := &frameStats{}
+	b.SetBytes(1 << 20)
+
+	for i := 0; i < b.N; i++ {
+		total := 0
+		for _, c := range chunks {
+			total += len(c)
+			stats.wrote(total)
+			stats.emitted(len(string(c)))
+		}
+		stats.sent = 0
+	}
+	stats.report(b)
+}
+
+func BenchmarkBatchedOutput(b *testing.B) {
+	chunks := heavyOutput()
+	stats := &frameStats{}
+	b.SetBytes(1 << 20)
+
+	for i := 0; i < b.N; i++ {
+		var batcher *Batcher
+		batcher = NewBatcher(DefaultBatcherOptions, func(frame string) {
+			stats.emitted(len(frame))
+			// Acknowledge as a receiver that keeps up would
+			batcher.Ack()
+		})
+
+		total := 0
+		for _, c := range chunks {
+			total += len(c)
+			stats.wrote(total)
+			batcher.Write(c)
+		}
+		batcher.Close()
+		stats.sent = 0
+	}
+	stats.report(b)
+}
+
+func TestBatcherInvalidUTF8(t *testing.T) {
+	const maxFrame = 16
+	var mu sync.Mutex
+	var frames []string
+	b := NewBatcher(BatcherOptions{Interval: time.Millisecond, MaxFrame: maxFrame}, func(frame string) {
+		mu.Lock()
+		frames = append(frames, frame)
+		mu.Unlock()
+	})
+
+	// Continuation bytes with no lead byte can't be cut at a character
+	// boundary, but must still go out
+	input := bytes.Repeat([]byte{0x80}, 2*maxFrame)
+	b.Write(input)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		total := 0
+		for _, f := range frames {
+			total += len(f)
+		}
+		mu.Unlock()
+		if total == len(input) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Sent %d of %d bytes", total, len(input))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	b.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, f := range frames {
+		if f == "" || len(f) > maxFrame {
+			t.Errorf("Frame of %d bytes", len(f))
+		}
+	}
+	if got := strings.Join(frames, ""); got != string(input) {
+		t.Errorf("Frames joined to %q", got)
+	}
+}
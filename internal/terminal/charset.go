@@ -0,0 +1,145 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/transform"
+)
+
+// maxPendingInput bounds the bytes held back for a character that hasn't
+// fully arrived; no supported charset uses longer sequences
+const maxPendingInput = 8
+
+// Charset converts a host's output from its character set to UTF-8 and
+// input from UTF-8 back. A nil Charset passes everything through unchanged.
+type Charset struct {
+	name string
+	enc  encoding.Encoding
+
+	mu      sync.Mutex
+	dec     transform.Transformer
+	pending []byte // start of a character split across reads
+}
+
+// NewCharset returns a converter for the named character set, such as
+// "ISO-8859-9", "windows-1254" or "Shift_JIS". It returns nil for UTF-8 or
+// an empty name, where no conversion is needed.
+func NewCharset(name string) (*Charset, error) {
+	enc, err := LookupCharset(name)
+	if err != nil || enc == nil {
+		return nil, err
+	}
+	return &Charset{name: name, enc: enc, dec: enc.NewDecoder()}, nil
+}
+
+// LookupCharset finds an encoding by its WHATWG or IANA name. It returns
+// nil for UTF-8 or an empty name.
+func LookupCharset(name string) (encoding.Encoding, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		enc, err = ianaindex.IANA.Encoding(name)
+	}
+	if err != nil || enc == nil {
+		return nil, fmt.Errorf("unknown character set %q", name)
+	}
+	if canonical, _ := htmlindex.Name(enc); canonical == "utf-8" {
+		return nil, nil
+	}
+	return enc, nil
+}
+
+// Name returns the character set name the converter was created with
+func (c *Charset) Name() string {
+	if c == nil {
+		return ""
+	}
+	return c.name
+}
+
+// Decode converts output from the host to UTF-8. A character cut off at the
+// end of p is kept and completed by the next call.
+func (c *Charset) Decode(p []byte) []byte {
+	if c == nil {
+		return p
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	src := p
+	if len(c.pending) > 0 {
+		src = append(c.pending, p...)
+		c.pending = nil
+	}
+
+	out := make([]byte, 0, len(src)*2)
+	buf := make([]byte, 4096)
+	for {
+		nDst, nSrc, err := c.dec.Transform(buf, src, false)
+		out = append(out, buf[:nDst]...)
+		src = src[nSrc:]
+		if errors.Is(err, transform.ErrShortDst) && (nDst > 0 || nSrc > 0) {
+			continue
+		}
+		if err != nil && !errors.Is(err, transform.ErrShortSrc) {
+			// Decoders replace bad bytes, so this shouldn't happen; don't lose output
+			out = append(out, src...)
+			src = nil
+		}
+		break
+	}
+
+	if len(src) > maxPendingInput {
+		// Not a split character after all
+		out = append(out, []byte(strings.ToValidUTF8(string(src), "�"))...)
+		src = nil
+		c.dec.Reset()
+	}
+	c.pending = append([]byte(nil), src...)
+	return out
+}
+
+// Encode converts input typed as UTF-8 to the host's character set.
+// Characters the host can't represent become its replacement character.
+func (c *Charset) Encode(p []byte) []byte {
+	if c == nil {
+		return p
+	}
+	out, err := encoding.ReplaceUnsupported(c.enc.NewEncoder()).Bytes(p)
+	if err != nil {
+		return p
+	}
+	return out
+}
+
+// Writer returns a writer that encodes everything written to it before
+// passing it on to w
+func (c *Charset) Writer(w io.Writer) io.Writer {
+	if c == nil {
+		return w
+	}
+	return &charsetWriter{c: c, w: w}
+}
+
+type charsetWriter struct {
+	c *Charset
+	w io.Writer
+}
+
+func (cw *charsetWriter) Write(p []byte) (int, error) {
+	if _, err := cw.w.Write(cw.c.Encode(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
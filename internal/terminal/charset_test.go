@@ -0,0 +1,50 @@
+package terminal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCharsetDecodeSplit(t *testing.T) {
+	c, err := NewCharset("Shift_JIS")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 日本 with the second character cut between reads
+	got := string(c.Decode([]byte("$ \x93\xfa\x96"))) + string(c.Decode([]byte("\x7b\r\n")))
+	if got != "$ 日本\r\n" {
+		t.Errorf("Decoded %q", got)
+	}
+}
+
+func TestCharsetRoundTrip(t *testing.T) {
+	c, err := NewCharset("windows-1254")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.Encode([]byte("ğüş")); !bytes.Equal(got, []byte{0xf0, 0xfc, 0xfe}) {
+		t.Errorf("Encoded % x", got)
+	}
+	if got := string(c.Decode([]byte{0xf0, 0xfc, 0xfe})); got != "ğüş" {
+		t.Errorf("Decoded %q", got)
+	}
+}
+
+func TestCharsetLookup(t *testing.T) {
+	for _, name := range []string{"", "utf-8", "UTF8"} {
+		if c, err := NewCharset(name); c != nil || err != nil {
+			t.Errorf("%q: got %v, %v; want no conversion", name, c, err)
+		}
+	}
+	if _, err := NewCharset("klingon"); err == nil {
+		t.Error("Unknown charset accepted")
+	}
+
+	// A nil converter passes data through
+	var c *Charset
+	if got := c.Decode([]byte("abc")); string(got) != "abc" {
+		t.Errorf("Nil decode changed data: %q", got)
+	}
+}
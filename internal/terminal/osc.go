@@ -0,0 +1,127 @@
+package terminal
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Longer OSC payloads are dropped; the ones we care about are short
+const maxOSCPayload = 4096
+
+// OSC is an operating system command sequence, ESC ] code ; data terminator
+type OSC struct {
+	Code string
+	Data string
+}
+
+// OSC scanner states
+const (
+	oscGround = iota
+	oscEscape
+	oscPayload
+	oscPayloadEsc
+)
+
+// OSCScanner picks OSC sequences out of a byte stream. Like Stripper it
+// keeps state between calls, so sequences split across reads are found.
+type OSCScanner struct {
+	state    int
+	buf      []byte
+	overflow bool
+}
+
+// Scan returns the OSC sequences completed within p
+func (s *OSCScanner) Scan(p []byte) []OSC {
+	var found []OSC
+	for _, b := range p {
+		if o, ok := s.Feed(b); ok {
+			found = append(found, o)
+		}
+	}
+	return found
+}
+
+// Feed advances the scanner by one byte and reports a sequence it completes.
+// Use it instead of Scan to know where in the stream a sequence ended.
+func (s *OSCScanner) Feed(b byte) (OSC, bool) {
+	switch s.state {
+	case oscGround:
+		if b == 0x1b {
+			s.state = oscEscape
+		}
+	case oscEscape:
+		switch b {
+		case ']':
+			s.start()
+		case 0x1b:
+		default:
+			s.state = oscGround
+		}
+	case oscPayload:
+		switch b {
+		case 0x07:
+			return s.finish()
+		case 0x1b:
+			s.state = oscPayloadEsc
+		default:
+			if len(s.buf) < maxOSCPayload {
+				s.buf = append(s.buf, b)
+			} else {
+				s.overflow = true
+			}
+		}
+	case oscPayloadEsc:
+		switch b {
+		case '\\':
+			return s.finish()
+		case ']':
+			// ESC inside the payload starts a new sequence
+			s.start()
+		default:
+			s.state = oscEscape
+		}
+	}
+	return OSC{}, false
+}
+
+func (s *OSCScanner) start() {
+	s.state = oscPayload
+	s.buf = s.buf[:0]
+	s.overflow = false
+}
+
+func (s *OSCScanner) finish() (OSC, bool) {
+	s.state = oscGround
+	if s.overflow {
+		return OSC{}, false
+	}
+	code, data, _ := strings.Cut(string(s.buf), ";")
+	return OSC{Code: code, Data: data}, true
+}
+
+// WorkingDir returns the directory announced by OSC 7 (file://host/path)
+// or iTerm2's OSC 1337 CurrentDir=path
+func (o OSC) WorkingDir() (string, bool) {
+	switch o.Code {
+	case "7":
+		rest, ok := strings.CutPrefix(o.Data, "file://")
+		if !ok {
+			return "", false
+		}
+		// Skip the host; shells print $PWD as is, so unescaping is best effort
+		i := strings.IndexByte(rest, '/')
+		if i < 0 {
+			return "", false
+		}
+		dir := rest[i:]
+		if unescaped, err := url.PathUnescape(dir); err == nil {
+			dir = unescaped
+		}
+		return dir, true
+
+	case "1337":
+		dir, ok := strings.CutPrefix(o.Data, "CurrentDir=")
+		return dir, ok && dir != ""
+	}
+	return "", false
+}
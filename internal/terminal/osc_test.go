@@ -0,0 +1,22 @@
+package terminal
+
+import "testing"
+
+func TestOSCWorkingDir(t *testing.T) {
+	var s OSCScanner
+	var found []OSC
+
+	// Sequences split across reads, with both terminators and other OSCs around
+	found = append(found, s.Scan([]byte("prompt\x1b]0;title\x07\x1b]7;file://host/home/bo"))...)
+	found = append(found, s.Scan([]byte("b/my%20dir\x1b\\$ ls\r\n\x1b]1337;CurrentDir=/srv/app\x07"))...)
+
+	var dirs []string
+	for _, o := range found {
+		if dir, ok := o.WorkingDir(); ok {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(found) != 3 || len(dirs) != 2 || dirs[0] != "/home/bob/my dir" || dirs[1] != "/srv/app" {
+		t.Errorf("Unexpected sequences %+v, dirs %q", found, dirs)
+	}
+}
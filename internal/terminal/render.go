@@ -0,0 +1,164 @@
+package terminal
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// transcript returns the scrollback followed by the active screen, without
+// the blank rows below the last line of output
+func (s *Screen) transcript() []Line {
+	lines := append(append([]Line(nil), s.scrollback...), s.active.lines...)
+
+	end := len(lines)
+	for end > 0 && lines[end-1].String() == "" && end-1 > len(s.scrollback)+s.active.y {
+		end--
+	}
+	return lines[:end]
+}
+
+// Text renders the session as plain text. Lines that were soft-wrapped at
+// the right margin are joined back together.
+func (s *Screen) Text() string {
+	var b strings.Builder
+	for _, l := range s.transcript() {
+		b.WriteString(l.String())
+		if !l.Wrapped {
+			b.WriteByte('\n')
+		}
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// basePalette is xterm's default 16 colors
+var basePalette = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+const (
+	htmlForeground = "#e5e5e5"
+	htmlBackground = "#000000"
+)
+
+// CSS returns the color as a CSS hex value
+func (c Color) CSS(def string) string {
+	switch {
+	case c == ColorDefault:
+		return def
+	case c.IsRGB():
+		return fmt.Sprintf("#%06x", int32(c)&0xffffff)
+	case c < 16:
+		return basePalette[c]
+	case c < 232:
+		// 6x6x6 color cube
+		levels := [6]int{0, 95, 135, 175, 215, 255}
+		i := int(c) - 16
+		return fmt.Sprintf("#%02x%02x%02x", levels[i/36], levels[(i/6)%6], levels[i%6])
+	default:
+		gray := 8 + (int(c)-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", gray, gray, gray)
+	}
+}
+
+func (st Style) css() string {
+	fg, bg := st.FG, st.BG
+	// Bold brightens the 8 basic colors, as most terminals do
+	if st.Flags&Bold != 0 && fg >= 0 && fg < 8 {
+		fg += 8
+	}
+
+	fgCSS, bgCSS := fg.CSS(htmlForeground), bg.CSS("")
+	if st.Flags&Reverse != 0 {
+		fgCSS, bgCSS = bg.CSS(htmlBackground), fg.CSS(htmlForeground)
+	}
+
+	var parts []string
+	if fgCSS != htmlForeground {
+		parts = append(parts, "color:"+fgCSS)
+	}
+	if bgCSS != "" {
+		parts = append(parts, "background:"+bgCSS)
+	}
+	if st.Flags&Bold != 0 {
+		parts = append(parts, "font-weight:bold")
+	}
+	if st.Flags&Faint != 0 {
+		parts = append(parts, "opacity:0.6")
+	}
+	if st.Flags&Italic != 0 {
+		parts = append(parts, "font-style:italic")
+	}
+	var deco []string
+	if st.Flags&Underline != 0 {
+		deco = append(deco, "underline")
+	}
+	if st.Flags&Strike != 0 {
+		deco = append(deco, "line-through")
+	}
+	if len(deco) > 0 {
+		parts = append(parts, "text-decoration:"+strings.Join(deco, " "))
+	}
+	return strings.Join(parts, ";")
+}
+
+// HTML renders the session as a self-contained page with colors preserved
+func (s *Screen) HTML(title string) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title))
+	fmt.Fprintf(&b, "<style>\nbody { margin: 0; background: %s; }\n", htmlBackground)
+	fmt.Fprintf(&b, "pre { margin: 0; padding: 1em; color: %s; font: 13px/1.3 'JetBrains Mono', Consolas, monospace; }\n", htmlForeground)
+	b.WriteString("</style>\n</head>\n<body>\n<pre>")
+
+	for _, l := range s.transcript() {
+		writeHTMLLine(&b, l)
+		if !l.Wrapped {
+			b.WriteByte('\n')
+		}
+	}
+
+	b.WriteString("</pre>\n</body>\n</html>\n")
+	return b.String()
+}
+
+// writeHTMLLine writes a line as runs of equally styled text
+func writeHTMLLine(b *strings.Builder, l Line) {
+	// Trailing blanks only matter when they're colored
+	end := len(l.Cells)
+	for !l.Wrapped && end > 0 {
+		c := l.Cells[end-1]
+		if (c.Ch != ' ' && c.Ch != 0) || c.Comb != "" || c.Style.BG != ColorDefault {
+			break
+		}
+		end--
+	}
+
+	var run strings.Builder
+	cur := ""
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		if cur == "" {
+			b.WriteString(html.EscapeString(run.String()))
+		} else {
+			fmt.Fprintf(b, "<span style=\"%s\">%s</span>", cur, html.EscapeString(run.String()))
+		}
+		run.Reset()
+	}
+
+	for _, c := range l.Cells[:end] {
+		if c.Ch == 0 {
+			continue
+		}
+		if css := c.Style.css(); css != cur {
+			flush()
+			cur = css
+		}
+		run.WriteRune(c.Ch)
+		run.WriteString(c.Comb)
+	}
+	flush()
+}
@@ -0,0 +1,789 @@
+package terminal
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+// Color is a palette index (0-255), an RGB value made with RGB, or ColorDefault
+type Color int32
+
+// ColorDefault is the terminal's default foreground or background
+const ColorDefault Color = -1
+
+const colorRGB = 1 << 24
+
+// RGB makes a true color value
+func RGB(r, g, b uint8) Color {
+	return Color(colorRGB | int32(r)<<16 | int32(g)<<8 | int32(b))
+}
+
+// IsRGB reports whether c is a true color value
+func (c Color) IsRGB() bool {
+	return c >= colorRGB
+}
+
+// Style flags
+const (
+	Bold uint8 = 1 << iota
+	Faint
+	Italic
+	Underline
+	Reverse
+	Strike
+)
+
+// Style is the rendition of a cell
+type Style struct {
+	FG    Color
+	BG    Color
+	Flags uint8
+}
+
+var defaultStyle = Style{FG: ColorDefault, BG: ColorDefault}
+
+// Cell is one character position on the screen
+type Cell struct {
+	Ch    rune   // 0 for the right half of a wide character
+	Comb  string // combining marks drawn over Ch
+	Style Style
+}
+
+// Line is a row of cells. Wrapped is set when the text continues on the
+// next line because it hit the right margin.
+type Line struct {
+	Cells   []Cell
+	Wrapped bool
+}
+
+type cursor struct {
+	x, y        int
+	style       Style
+	lineDrawing bool
+}
+
+type buffer struct {
+	lines    []Line
+	x, y     int
+	wrapNext bool // the next printable character wraps first
+	saved    cursor
+}
+
+// parser states
+const (
+	psGround = iota
+	psEscape
+	psCSI
+	psString
+	psStringEsc
+	psCharset
+	psSkipOne
+)
+
+// DefaultScrollback is how many lines scrolled off the main screen are kept
+const DefaultScrollback = 100000
+
+// Screen is a VT100/xterm emulator that keeps what has scrolled off the
+// main screen, so a session can be rendered the way it looked
+type Screen struct {
+	cols, rows    int
+	main, alt     buffer
+	active        *buffer
+	top, bottom   int // scroll region, inclusive
+	style         Style
+	autowrap      bool
+	lineDrawing   bool
+	lastChar      rune
+	scrollback    []Line
+	MaxScrollback int
+
+	state    int
+	params   []byte
+	private  byte
+	charsetG byte
+	utf8buf  []byte
+}
+
+// NewScreen creates an emulator with the given size
+func NewScreen(cols, rows int) *Screen {
+	if cols <= 0 {
+		cols = 80
+	}
+	if rows <= 0 {
+		rows = 24
+	}
+	s := &Screen{
+		cols:          cols,
+		rows:          rows,
+		MaxScrollback: DefaultScrollback,
+	}
+	s.reset()
+	return s
+}
+
+func (s *Screen) reset() {
+	s.main = buffer{lines: blankLines(s.cols, s.rows, defaultStyle)}
+	s.alt = buffer{lines: blankLines(s.cols, s.rows, defaultStyle)}
+	s.active = &s.main
+	s.top, s.bottom = 0, s.rows-1
+	s.style = defaultStyle
+	s.autowrap = true
+	s.lineDrawing = false
+}
+
+func blankLine(cols int, style Style) Line {
+	cells := make([]Cell, cols)
+	for i := range cells {
+		cells[i] = Cell{Ch: ' ', Style: Style{FG: ColorDefault, BG: style.BG}}
+	}
+	return Line{Cells: cells}
+}
+
+func blankLines(cols, rows int, style Style) []Line {
+	lines := make([]Line, rows)
+	for i := range lines {
+		lines[i] = blankLine(cols, style)
+	}
+	return lines
+}
+
+// Size returns the screen size
+func (s *Screen) Size() (cols, rows int) {
+	return s.cols, s.rows
+}
+
+// Cursor returns the cursor position on the active screen
+func (s *Screen) Cursor() (x, y int) {
+	return s.active.x, s.active.y
+}
+
+// AltScreen reports whether the alternate screen is active
+func (s *Screen) AltScreen() bool {
+	return s.active == &s.alt
+}
+
+// Lines returns the active screen
+func (s *Screen) Lines() []Line {
+	return s.active.lines
+}
+
+// Scrollback returns the lines that have scrolled off the main screen
+func (s *Screen) Scrollback() []Line {
+	return s.scrollback
+}
+
+// Resize changes the screen size. Lines that no longer fit above the cursor
+// move to the scrollback; text isn't reflowed.
+func (s *Screen) Resize(cols, rows int) {
+	if cols <= 0 || rows <= 0 || (cols == s.cols && rows == s.rows) {
+		return
+	}
+
+	for _, b := range []*buffer{&s.main, &s.alt} {
+		for b.y >= rows {
+			if b == &s.main {
+				s.pushScrollback(b.lines[0])
+			}
+			b.lines = b.lines[1:]
+			b.y--
+		}
+		if len(b.lines) > rows {
+			b.lines = b.lines[:rows]
+		}
+		for len(b.lines) < rows {
+			b.lines = append(b.lines, blankLine(cols, defaultStyle))
+		}
+		for i := range b.lines {
+			b.lines[i].Cells = resizeCells(b.lines[i].Cells, cols)
+		}
+		b.x = min(b.x, cols-1)
+		b.wrapNext = false
+	}
+
+	s.cols, s.rows = cols, rows
+	s.top, s.bottom = 0, rows-1
+}
+
+func resizeCells(cells []Cell, cols int) []Cell {
+	if len(cells) >= cols {
+		return cells[:cols]
+	}
+	for len(cells) < cols {
+		cells = append(cells, Cell{Ch: ' ', Style: defaultStyle})
+	}
+	return cells
+}
+
+func (s *Screen) pushScrollback(l Line) {
+	if s.MaxScrollback <= 0 {
+		return
+	}
+	s.scrollback = append(s.scrollback, l)
+	if over := len(s.scrollback) - s.MaxScrollback; over > 0 {
+		s.scrollback = append([]Line(nil), s.scrollback[over:]...)
+	}
+}
+
+// Write feeds terminal output through the emulator
+func (s *Screen) Write(p []byte) (int, error) {
+	data := p
+	if len(s.utf8buf) > 0 {
+		data = append(s.utf8buf, p...)
+		s.utf8buf = nil
+	}
+
+	for i := 0; i < len(data); {
+		b := data[i]
+		if s.state == psGround && b >= utf8.RuneSelf {
+			if !utf8.FullRune(data[i:]) {
+				s.utf8buf = append([]byte(nil), data[i:]...)
+				break
+			}
+			r, size := utf8.DecodeRune(data[i:])
+			s.put(r)
+			i += size
+			continue
+		}
+		s.step(b)
+		i++
+	}
+	return len(p), nil
+}
+
+func (s *Screen) step(b byte) {
+	switch s.state {
+	case psGround:
+		switch {
+		case b == 0x1b:
+			s.state = psEscape
+		case b < 0x20:
+			s.control(b)
+		case b == 0x7f:
+		default:
+			s.put(rune(b))
+		}
+
+	case psEscape:
+		s.state = psGround
+		switch b {
+		case '[':
+			s.state = psCSI
+			s.params = s.params[:0]
+			s.private = 0
+		case ']', 'P', 'X', '^', '_':
+			s.state = psString
+		case '(', ')', '*', '+':
+			s.state = psCharset
+			s.charsetG = b
+		case '#', '%', ' ':
+			s.state = psSkipOne
+		case '7':
+			s.saveCursor()
+		case '8':
+			s.restoreCursor()
+		case 'D':
+			s.lineFeed()
+		case 'E':
+			s.active.x = 0
+			s.lineFeed()
+		case 'M':
+			s.reverseIndex()
+		case 'c':
+			s.reset()
+		}
+
+	case psCSI:
+		switch {
+		case b == 0x1b:
+			s.state = psEscape
+		case b < 0x20:
+			s.control(b)
+		case b >= '<' && b <= '?':
+			s.private = b
+		case (b >= '0' && b <= '9') || b == ';' || b == ':':
+			s.params = append(s.params, b)
+		case b >= 0x40 && b <= 0x7e:
+			s.state = psGround
+			s.csi(b)
+		}
+
+	case psString:
+		switch b {
+		case 0x07:
+			s.state = psGround
+		case 0x1b:
+			s.state = psStringEsc
+		}
+
+	case psStringEsc:
+		if b == '\\' {
+			s.state = psGround
+		} else {
+			s.state = psString
+		}
+
+	case psCharset:
+		s.state = psGround
+		if s.charsetG == '(' {
+			s.lineDrawing = b == '0'
+		}
+
+	case psSkipOne:
+		s.state = psGround
+	}
+}
+
+func (s *Screen) control(b byte) {
+	a := s.active
+	switch b {
+	case '\b':
+		if a.x > 0 {
+			a.x--
+		}
+		a.wrapNext = false
+	case '\t':
+		a.x = min((a.x/8+1)*8, s.cols-1)
+		a.wrapNext = false
+	case '\n', '\v', '\f':
+		s.lineFeed()
+	case '\r':
+		a.x = 0
+		a.wrapNext = false
+	}
+}
+
+// decGraphics maps the DEC special graphics set used for line drawing
+var decGraphics = map[rune]rune{
+	'j': '┘', 'k': '┐', 'l': '┌', 'm': '└', 'n': '┼', 'q': '─',
+	't': '├', 'u': '┤', 'v': '┴', 'w': '┬', 'x': '│', 'a': '▒',
+	'`': '◆', 'f': '°', 'g': '±', '~': '·', 'y': '≤', 'z': '≥',
+}
+
+func runeWidth(r rune) int {
+	if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || r == 0x200b {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+func (s *Screen) put(r rune) {
+	if s.lineDrawing {
+		if g, ok := decGraphics[r]; ok {
+			r = g
+		}
+	}
+
+	a := s.active
+	w := runeWidth(r)
+	if w == 0 {
+		// Combining mark: attach to the character before the cursor
+		x := a.x
+		if !a.wrapNext && x > 0 {
+			x--
+		}
+		if x < s.cols && a.lines[a.y].Cells[x].Ch == 0 && x > 0 {
+			x--
+		}
+		a.lines[a.y].Cells[x].Comb += string(r)
+		return
+	}
+
+	if a.wrapNext || (w == 2 && a.x == s.cols-1) {
+		if s.autowrap {
+			a.lines[a.y].Wrapped = true
+			a.x = 0
+			s.lineFeed()
+		}
+		a.wrapNext = false
+	}
+
+	cells := a.lines[a.y].Cells
+	cells[a.x] = Cell{Ch: r, Style: s.style}
+	if w == 2 && a.x+1 < s.cols {
+		cells[a.x+1] = Cell{Ch: 0, Style: s.style}
+	}
+	s.lastChar = r
+
+	a.x += w
+	if a.x >= s.cols {
+		a.x = s.cols - 1
+		a.wrapNext = true
+	}
+}
+
+func (s *Screen) lineFeed() {
+	a := s.active
+	a.wrapNext = false
+	if a.y == s.bottom {
+		s.scrollUp(1)
+	} else if a.y < s.rows-1 {
+		a.y++
+	}
+}
+
+func (s *Screen) reverseIndex() {
+	a := s.active
+	a.wrapNext = false
+	if a.y == s.top {
+		s.scrollDown(1)
+	} else if a.y > 0 {
+		a.y--
+	}
+}
+
+// scrollUp moves the scroll region up, keeping lines that leave a full-height
+// main screen in the scrollback
+func (s *Screen) scrollUp(n int) {
+	a := s.active
+	n = min(n, s.bottom-s.top+1)
+	for i := 0; i < n; i++ {
+		if a == &s.main && s.top == 0 {
+			s.pushScrollback(a.lines[s.top])
+		}
+		copy(a.lines[s.top:s.bottom], a.lines[s.top+1:s.bottom+1])
+		a.lines[s.bottom] = blankLine(s.cols, s.style)
+	}
+}
+
+func (s *Screen) scrollDown(n int) {
+	a := s.active
+	n = min(n, s.bottom-s.top+1)
+	for i := 0; i < n; i++ {
+		copy(a.lines[s.top+1:s.bottom+1], a.lines[s.top:s.bottom])
+		a.lines[s.top] = blankLine(s.cols, s.style)
+	}
+}
+
+func (s *Screen) saveCursor() {
+	a := s.active
+	a.saved = cursor{x: a.x, y: a.y, style: s.style, lineDrawing: s.lineDrawing}
+}
+
+func (s *Screen) restoreCursor() {
+	a := s.active
+	a.x = min(a.saved.x, s.cols-1)
+	a.y = min(a.saved.y, s.rows-1)
+	a.wrapNext = false
+	s.style = a.saved.style
+	s.lineDrawing = a.saved.lineDrawing
+}
+
+// paramList parses CSI parameters; missing ones are 0
+func (s *Screen) paramList() []int {
+	var list []int
+	cur, has := 0, false
+	for _, b := range s.params {
+		if b == ';' || b == ':' {
+			list = append(list, cur)
+			cur, has = 0, false
+			continue
+		}
+		if cur < 1<<16 {
+			cur = cur*10 + int(b-'0')
+		}
+		has = true
+	}
+	if has || len(list) > 0 {
+		list = append(list, cur)
+	}
+	return list
+}
+
+func param(list []int, i, def int) int {
+	if i < len(list) && list[i] > 0 {
+		return list[i]
+	}
+	return def
+}
+
+func (s *Screen) erase(y, from, to int) {
+	cells := s.active.lines[y].Cells
+	for x := max(from, 0); x < min(to, s.cols); x++ {
+		cells[x] = Cell{Ch: ' ', Style: Style{FG: ColorDefault, BG: s.style.BG}}
+	}
+	if to >= s.cols {
+		s.active.lines[y].Wrapped = false
+	}
+}
+
+func (s *Screen) csi(final byte) {
+	p := s.paramList()
+	a := s.active
+
+	if s.private == '?' {
+		if final == 'h' || final == 'l' {
+			for _, mode := range p {
+				s.setPrivateMode(mode, final == 'h')
+			}
+		}
+		return
+	}
+	if s.private != 0 {
+		return
+	}
+
+	if final != 'm' {
+		a.wrapNext = false
+	}
+
+	switch final {
+	case 'A':
+		// Vertical moves stop at the scroll region margins when inside it
+		if a.y >= s.top {
+			a.y = max(a.y-param(p, 0, 1), s.top)
+		} else {
+			a.y = max(a.y-param(p, 0, 1), 0)
+		}
+	case 'B':
+		if a.y <= s.bottom {
+			a.y = min(a.y+param(p, 0, 1), s.bottom)
+		} else {
+			a.y = min(a.y+param(p, 0, 1), s.rows-1)
+		}
+	case 'C':
+		a.x = min(a.x+param(p, 0, 1), s.cols-1)
+	case 'D':
+		a.x = max(a.x-param(p, 0, 1), 0)
+	case 'E':
+		a.y = min(a.y+param(p, 0, 1), s.rows-1)
+		a.x = 0
+	case 'F':
+		a.y = max(a.y-param(p, 0, 1), 0)
+		a.x = 0
+	case 'G', '`':
+		a.x = clamp(param(p, 0, 1)-1, 0, s.cols-1)
+	case 'd':
+		a.y = clamp(param(p, 0, 1)-1, 0, s.rows-1)
+	case 'H', 'f':
+		a.y = clamp(param(p, 0, 1)-1, 0, s.rows-1)
+		a.x = clamp(param(p, 1, 1)-1, 0, s.cols-1)
+	case 'J':
+		switch param(p, 0, 0) {
+		case 0:
+			s.erase(a.y, a.x, s.cols)
+			for y := a.y + 1; y < s.rows; y++ {
+				s.erase(y, 0, s.cols)
+			}
+		case 1:
+			for y := 0; y < a.y; y++ {
+				s.erase(y, 0, s.cols)
+			}
+			s.erase(a.y, 0, a.x+1)
+		case 2:
+			for y := 0; y < s.rows; y++ {
+				s.erase(y, 0, s.cols)
+			}
+		case 3:
+			s.scrollback = nil
+		}
+	case 'K':
+		switch param(p, 0, 0) {
+		case 0:
+			s.erase(a.y, a.x, s.cols)
+		case 1:
+			s.erase(a.y, 0, a.x+1)
+		case 2:
+			s.erase(a.y, 0, s.cols)
+		}
+	case 'X':
+		s.erase(a.y, a.x, a.x+param(p, 0, 1))
+	case '@':
+		n := min(param(p, 0, 1), s.cols-a.x)
+		cells := a.lines[a.y].Cells
+		copy(cells[a.x+n:], cells[a.x:s.cols-n])
+		s.erase(a.y, a.x, a.x+n)
+	case 'P':
+		n := min(param(p, 0, 1), s.cols-a.x)
+		cells := a.lines[a.y].Cells
+		copy(cells[a.x:], cells[a.x+n:])
+		s.erase(a.y, s.cols-n, s.cols)
+	case 'L', 'M':
+		if a.y < s.top || a.y > s.bottom {
+			return
+		}
+		top := s.top
+		s.top = a.y
+		if final == 'L' {
+			s.scrollDown(param(p, 0, 1))
+		} else {
+			// Deleted lines never go to the scrollback
+			n := min(param(p, 0, 1), s.bottom-a.y+1)
+			for i := 0; i < n; i++ {
+				copy(a.lines[s.top:s.bottom], a.lines[s.top+1:s.bottom+1])
+				a.lines[s.bottom] = blankLine(s.cols, s.style)
+			}
+		}
+		s.top = top
+		a.x = 0
+	case 'S':
+		s.scrollUp(param(p, 0, 1))
+	case 'T':
+		s.scrollDown(param(p, 0, 1))
+	case 'b':
+		if s.lastChar != 0 {
+			for i := 0; i < min(param(p, 0, 1), s.cols*s.rows); i++ {
+				s.put(s.lastChar)
+			}
+		}
+	case 'r':
+		top := param(p, 0, 1) - 1
+		bottom := param(p, 1, s.rows) - 1
+		if top < bottom && bottom < s.rows {
+			s.top, s.bottom = top, bottom
+			a.x, a.y = 0, 0
+		}
+	case 's':
+		s.saveCursor()
+	case 'u':
+		s.restoreCursor()
+	case 'm':
+		s.sgr(p)
+	}
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
+
+func (s *Screen) setPrivateMode(mode int, on bool) {
+	switch mode {
+	case 7:
+		s.autowrap = on
+	case 47, 1047:
+		s.switchScreen(on, mode == 1047 && !on)
+	case 1049:
+		if on {
+			s.saveCursor()
+			s.switchScreen(true, true)
+		} else {
+			s.switchScreen(false, false)
+			s.restoreCursor()
+		}
+	}
+}
+
+// switchScreen moves between the main and alternate screens
+func (s *Screen) switchScreen(alt, clear bool) {
+	if alt == s.AltScreen() {
+		return
+	}
+
+	if alt {
+		s.alt.x, s.alt.y = s.main.x, s.main.y
+		s.active = &s.alt
+		if clear {
+			s.alt.lines = blankLines(s.cols, s.rows, defaultStyle)
+		}
+	} else {
+		if clear {
+			s.alt.lines = blankLines(s.cols, s.rows, defaultStyle)
+		}
+		s.main.x, s.main.y = s.alt.x, s.alt.y
+		s.active = &s.main
+	}
+	s.active.wrapNext = false
+}
+
+func (s *Screen) sgr(p []int) {
+	if len(p) == 0 {
+		p = []int{0}
+	}
+
+	for i := 0; i < len(p); i++ {
+		switch n := p[i]; {
+		case n == 0:
+			s.style = defaultStyle
+		case n == 1:
+			s.style.Flags |= Bold
+		case n == 2:
+			s.style.Flags |= Faint
+		case n == 3:
+			s.style.Flags |= Italic
+		case n == 4:
+			s.style.Flags |= Underline
+		case n == 7:
+			s.style.Flags |= Reverse
+		case n == 9:
+			s.style.Flags |= Strike
+		case n == 22:
+			s.style.Flags &^= Bold | Faint
+		case n == 23:
+			s.style.Flags &^= Italic
+		case n == 24:
+			s.style.Flags &^= Underline
+		case n == 27:
+			s.style.Flags &^= Reverse
+		case n == 29:
+			s.style.Flags &^= Strike
+		case n >= 30 && n <= 37:
+			s.style.FG = Color(n - 30)
+		case n == 39:
+			s.style.FG = ColorDefault
+		case n >= 40 && n <= 47:
+			s.style.BG = Color(n - 40)
+		case n == 49:
+			s.style.BG = ColorDefault
+		case n >= 90 && n <= 97:
+			s.style.FG = Color(n - 90 + 8)
+		case n >= 100 && n <= 107:
+			s.style.BG = Color(n - 100 + 8)
+		case n == 38 || n == 48:
+			c, used := extendedColor(p[i+1:])
+			i += used
+			if c == nil {
+				continue
+			}
+			if n == 38 {
+				s.style.FG = *c
+			} else {
+				s.style.BG = *c
+			}
+		}
+	}
+}
+
+// extendedColor parses the arguments of SGR 38/48 and reports how many it used
+func extendedColor(p []int) (*Color, int) {
+	if len(p) == 0 {
+		return nil, 0
+	}
+	switch p[0] {
+	case 5:
+		if len(p) < 2 {
+			return nil, len(p)
+		}
+		c := Color(clamp(p[1], 0, 255))
+		return &c, 2
+	case 2:
+		if len(p) < 4 {
+			return nil, len(p)
+		}
+		c := RGB(uint8(p[1]), uint8(p[2]), uint8(p[3]))
+		return &c, 4
+	}
+	return nil, 1
+}
+
+// String renders a line as text. Trailing blanks are dropped unless the
+// line wraps, where they are part of the text.
+func (l Line) String() string {
+	buf := make([]byte, 0, len(l.Cells))
+	end := 0
+	for _, c := range l.Cells {
+		if c.Ch == 0 {
+			continue
+		}
+		buf = utf8.AppendRune(buf, c.Ch)
+		buf = append(buf, c.Comb...)
+		if c.Ch != ' ' || c.Comb != "" || l.Wrapped {
+			end = len(buf)
+		}
+	}
+	return string(buf[:end])
+}
@@ -0,0 +1,70 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+)
+
+func screenLines(s *Screen) []string {
+	var lines []string
+	for _, l := range s.Lines() {
+		lines = append(lines, l.String())
+	}
+	return lines
+}
+
+func TestScreenCursorAndErase(t *testing.T) {
+	s := NewScreen(20, 4)
+	s.Write([]byte("hello world\r\nsecond line"))
+	s.Write([]byte("\x1b[1;7Hthere"))  // overwrite "world"
+	s.Write([]byte("\x1b[2;7H\x1b[K")) // cut "line"
+	s.Write([]byte("\x1b[4;1Hlast\x1b[3D\x1b[1P"))
+
+	want := []string{"hello there", "second", "", "lst"}
+	if got := screenLines(s); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestScreenScrollbackAndWrap(t *testing.T) {
+	s := NewScreen(10, 3)
+	s.Write([]byte("one\r\ntwo\r\nthree\r\nfour\r\n"))
+	s.Write([]byte("0123456789abc"))
+
+	if got := s.Text(); got != "one\ntwo\nthree\nfour\n0123456789abc\n" {
+		t.Errorf("Unexpected transcript %q", got)
+	}
+
+	// A full clear wipes the screen but not what already scrolled away
+	s.Write([]byte("\x1b[H\x1b[2Jdone"))
+	if got := s.Text(); !strings.HasPrefix(got, "one\ntwo\n") || !strings.HasSuffix(got, "done\n") {
+		t.Errorf("Unexpected transcript after clear %q", got)
+	}
+}
+
+func TestScreenAltScreen(t *testing.T) {
+	s := NewScreen(20, 5)
+	s.Write([]byte("$ vim notes\r\n"))
+	s.Write([]byte("\x1b[?1049h\x1b[H\x1b[2J~\r\n~\r\n\x1b[5;1H-- INSERT --"))
+	if !s.AltScreen() {
+		t.Fatal("Expected alternate screen")
+	}
+
+	s.Write([]byte("\x1b[?1049l$ "))
+	if got := s.Text(); got != "$ vim notes\n$\n" {
+		t.Errorf("Editor output leaked into transcript: %q", got)
+	}
+	if x, y := s.Cursor(); x != 2 || y != 1 {
+		t.Errorf("Cursor not restored, at %d,%d", x, y)
+	}
+}
+
+func TestScreenHTML(t *testing.T) {
+	s := NewScreen(20, 2)
+	s.Write([]byte("\x1b[1;31mFAILED\x1b[0m <ok>"))
+
+	out := s.HTML("build")
+	if !strings.Contains(out, `<span style="color:#ff0000;font-weight:bold">FAILED</span> &lt;ok&gt;`) {
+		t.Errorf("Unexpected HTML:\n%s", out)
+	}
+}
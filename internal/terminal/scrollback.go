@@ -0,0 +1,169 @@
+package terminal
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultScrollbackSize is how much output a session keeps by default
+const DefaultScrollbackSize = 1 << 20
+
+// Scrollback keeps the most recent output of a terminal in a bounded ring
+// buffer so a view can be rebuilt and searched
+type Scrollback struct {
+	mu      sync.Mutex
+	buf     []byte
+	start   int  // index of the oldest byte
+	size    int  // bytes in use
+	trimmed bool // older output has been overwritten
+}
+
+// Match is a search hit. Line counts from the first line of Snapshot and
+// Start/End are the cell columns the hit spans in the line's visible text,
+// as a terminal lays it out.
+type Match struct {
+	Line  int    `json:"line"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+// NewScrollback creates a buffer holding up to capacity bytes
+func NewScrollback(capacity int) *Scrollback {
+	if capacity <= 0 {
+		capacity = DefaultScrollbackSize
+	}
+	return &Scrollback{buf: make([]byte, capacity)}
+}
+
+// Write appends output, overwriting the oldest bytes when full
+func (s *Scrollback) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(p)
+	capacity := len(s.buf)
+	if len(p) >= capacity {
+		p = p[len(p)-capacity:]
+		copy(s.buf, p)
+		s.start, s.size = 0, capacity
+		s.trimmed = true
+		return n, nil
+	}
+
+	end := (s.start + s.size) % capacity
+	written := copy(s.buf[end:], p)
+	copy(s.buf, p[written:])
+
+	s.size += len(p)
+	if s.size > capacity {
+		s.start = (s.start + s.size - capacity) % capacity
+		s.size = capacity
+		s.trimmed = true
+	}
+	return n, nil
+}
+
+// Snapshot returns the buffered output in order. Once old output has been
+// overwritten it starts at the first full line, so a view rebuilt from it
+// doesn't begin inside an escape sequence or character.
+func (s *Scrollback) Snapshot() []byte {
+	s.mu.Lock()
+	out := make([]byte, s.size)
+	n := copy(out, s.buf[s.start:min(s.start+s.size, len(s.buf))])
+	copy(out[n:], s.buf[:s.size-n])
+	trimmed := s.trimmed
+	s.mu.Unlock()
+
+	if trimmed {
+		if i := bytes.IndexByte(out, '\n'); i >= 0 {
+			out = out[i+1:]
+		}
+	}
+	return out
+}
+
+// Reset discards all buffered output
+func (s *Scrollback) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start, s.size, s.trimmed = 0, 0, false
+}
+
+// Search finds query in the visible text of the buffered output. The query
+// is a regular expression when regex is set. At most limit matches are
+// returned, 0 means no limit.
+func (s *Scrollback) Search(query string, regex, caseSensitive bool, limit int) ([]Match, error) {
+	pattern := query
+	if !regex {
+		pattern = regexp.QuoteMeta(query)
+	}
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	text := StripANSI(string(s.Snapshot()))
+	var matches []Match
+	for i, line := range strings.Split(text, "\n") {
+		line = visibleLine(line)
+		for _, loc := range re.FindAllStringIndex(line, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			matches = append(matches, Match{
+				Line:  i,
+				Start: columns(line[:loc[0]]),
+				End:   columns(line[:loc[1]]),
+				Text:  line,
+			})
+			if limit > 0 && len(matches) >= limit {
+				return matches, nil
+			}
+		}
+	}
+	return matches, nil
+}
+
+// columns is how many cells the start of a line takes up, with tabs
+// advancing to the next multiple of 8
+func columns(text string) int {
+	n := 0
+	for _, r := range text {
+		if r == '\t' {
+			n += 8 - n%8
+			continue
+		}
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// visibleLine approximates what a line shows after carriage returns and
+// backspaces: text after the last bare \r overwrote what came before it
+func visibleLine(line string) string {
+	line = strings.TrimRight(line, "\r")
+	if i := strings.LastIndexByte(line, '\r'); i >= 0 {
+		line = line[i+1:]
+	}
+	if !strings.ContainsRune(line, '\b') {
+		return line
+	}
+
+	out := make([]rune, 0, len(line))
+	for _, r := range line {
+		if r == '\b' {
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+			continue
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
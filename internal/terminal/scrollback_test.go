@@ -0,0 +1,66 @@
+package terminal
+
+import (
+	"testing"
+)
+
+func TestScrollbackRing(t *testing.T) {
+	s := NewScrollback(14)
+	s.Write([]byte("first line\r\n"))
+	s.Write([]byte("second\r\nthird\r\n"))
+
+	// The oldest output is gone and the partial line before "third" is dropped
+	if got := string(s.Snapshot()); got != "third\r\n" {
+		t.Errorf("Unexpected snapshot %q", got)
+	}
+}
+
+func TestScrollbackSearch(t *testing.T) {
+	s := NewScrollback(1024)
+	s.Write([]byte("make all\r\n\x1b[31mBuild FAILED\x1b[0m in 3s\r\nretry: build ok\r\n"))
+
+	matches, err := s.Search("build", false, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || matches[0].Line != 1 || matches[0].Start != 0 || matches[1].Line != 2 || matches[1].Start != 7 {
+		t.Errorf("Unexpected matches %+v", matches)
+	}
+
+	matches, err = s.Search(`in \d+s`, true, true, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Text != "Build FAILED in 3s" {
+		t.Errorf("Unexpected regex matches %+v", matches)
+	}
+}
+
+func TestScrollbackSearchColumns(t *testing.T) {
+	s := NewScrollback(1024)
+	s.Write([]byte("héllo wörld\r\n日本語 error\r\nx\terror\r\ne\u0301 error\r\n"))
+
+	matches, err := s.Search("error", false, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Accented, double-width and combining characters and tabs shift the
+	// match by the cells they take, not their bytes
+	want := [][2]int{{7, 12}, {8, 13}, {2, 7}}
+	if len(matches) != len(want) {
+		t.Fatalf("Unexpected matches %+v", matches)
+	}
+	for i, m := range matches {
+		if m.Start != want[i][0] || m.End != want[i][1] {
+			t.Errorf("Line %d: columns %d-%d, want %d-%d", m.Line, m.Start, m.End, want[i][0], want[i][1])
+		}
+	}
+
+	matches, err = s.Search("wörld", false, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Start != 6 || matches[0].End != 11 {
+		t.Errorf("Unexpected matches %+v", matches)
+	}
+}
@@ -0,0 +1,35 @@
+package terminal
+
+import "unicode/utf8"
+
+// SplitIncompleteUTF8 splits p before a trailing multi-byte character that
+// hasn't fully arrived yet, so it can be carried over to the next read.
+// Invalid bytes are left in complete; only a valid but cut-off prefix is held back.
+func SplitIncompleteUTF8(p []byte) (complete, tail []byte) {
+	// A UTF-8 sequence is at most 4 bytes, so only the last 3 can be a partial one
+	for i := 1; i <= 3 && i <= len(p); i++ {
+		b := p[len(p)-i]
+		if b < utf8.RuneSelf {
+			// ASCII, nothing after it can be a lead byte
+			return p, nil
+		}
+		if !utf8.RuneStart(b) {
+			// continuation byte, keep looking for the lead byte
+			continue
+		}
+		if !utf8.FullRune(p[len(p)-i:]) {
+			return p[:len(p)-i], p[len(p)-i:]
+		}
+		return p, nil
+	}
+	return p, nil
+}
+
+// StringWidth returns how many terminal cells s takes up
+func StringWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
@@ -0,0 +1,52 @@
+package terminal
+
+import (
+	"bytes"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitIncompleteUTF8(t *testing.T) {
+	tests := []struct {
+		in, complete, tail string
+	}{
+		{"abc", "abc", ""},
+		{"ab\xc3", "ab", "\xc3"},
+		{"ab\xe2\x9c", "ab", "\xe2\x9c"},
+		{"ab\xf0\x9f\x99", "ab", "\xf0\x9f\x99"},
+		{"ab\xf0\x9f\x99\x82", "ab\xf0\x9f\x99\x82", ""},
+		// Invalid bytes aren't held back
+		{"ab\x80\x80\x80\x80", "ab\x80\x80\x80\x80", ""},
+		{"a\xffb", "a\xffb", ""},
+	}
+	for _, tt := range tests {
+		complete, tail := SplitIncompleteUTF8([]byte(tt.in))
+		if string(complete) != tt.complete || string(tail) != tt.tail {
+			t.Errorf("SplitIncompleteUTF8(%q) = %q, %q; want %q, %q", tt.in, complete, tail, tt.complete, tt.tail)
+		}
+	}
+}
+
+func TestSplitIncompleteUTF8RoundTrip(t *testing.T) {
+	input := []byte("héllo wörld ✓ 🙂 ½ — done")
+	for size := 1; size <= 5; size++ {
+		var out, tail []byte
+		for i := 0; i < len(input); i += size {
+			end := i + size
+			if end > len(input) {
+				end = len(input)
+			}
+			var complete []byte
+			complete, tail = SplitIncompleteUTF8(append(tail, input[i:end]...))
+			if !utf8.Valid(complete) {
+				t.Fatalf("Chunks of %d: invalid piece %q", size, complete)
+			}
+			out = append(out, complete...)
+			tail = append([]byte(nil), tail...)
+		}
+		out = append(out, tail...)
+		if !bytes.Equal(out, input) {
+			t.Errorf("Chunks of %d: got %q", size, out)
+		}
+	}
+}
@@ -160,16 +160,22 @@ func (q *TransferQueue) SetOnChange(fn func()) {
 	q.onChange = fn
 }
 
+// notify calls the change callback. Callers must not hold q.mu: the
+// callback usually reads the queue back through GetItems, which would
+// deadlock, so every change unlocks before notifying.
 func (q *TransferQueue) notify() {
-	if q.onChange != nil {
-		q.onChange()
+	q.mu.Lock()
+	fn := q.onChange
+	q.mu.Unlock()
+
+	if fn != nil {
+		fn()
 	}
 }
 
 // AddDownload adds a download to the queue
 func (q *TransferQueue) AddDownload(remotePath, localPath string) *TransferItem {
 	q.mu.Lock()
-	defer q.mu.Unlock()
 
 	q.nextID++
 	item := &TransferItem{
@@ -183,6 +189,8 @@ func (q *TransferQueue) AddDownload(remotePath, localPath string) *TransferItem
 	}
 
 	q.items = append(q.items, item)
+	q.mu.Unlock()
+
 	go q.processNext()
 	q.notify()
 	return item
@@ -191,7 +199,6 @@ func (q *TransferQueue) AddDownload(remotePath, localPath string) *TransferItem
 // AddUpload adds an upload to the queue
 func (q *TransferQueue) AddUpload(localPath, remotePath string) *TransferItem {
 	q.mu.Lock()
-	defer q.mu.Unlock()
 
 	q.nextID++
 	item := &TransferItem{
@@ -205,15 +212,72 @@ func (q *TransferQueue) AddUpload(localPath, remotePath string) *TransferItem {
 	}
 
 	q.items = append(q.items, item)
+	q.mu.Unlock()
+
 	go q.processNext()
 	q.notify()
 	return item
 }
 
+// AddExternal adds a transfer carried out outside the queue, such as ZMODEM
+// inside a terminal. It starts in progress; the caller reports progress and
+// completion and watches Cancelled.
+func (q *TransferQueue) AddExternal(direction TransferDirection, name, remotePath, localPath string, total int64) *TransferItem {
+	q.mu.Lock()
+
+	q.nextID++
+	item := &TransferItem{
+		ID:         q.nextID,
+		FileName:   name,
+		RemotePath: remotePath,
+		LocalPath:  localPath,
+		Direction:  direction,
+		TotalBytes: total,
+		Status:     StatusInProgress,
+		StartTime:  time.Now().Format(time.RFC3339),
+		cancel:     make(chan struct{}),
+	}
+
+	q.items = append(q.items, item)
+	q.mu.Unlock()
+
+	q.notify()
+	return item
+}
+
+// SetProgress updates the bytes moved by an external transfer
+func (q *TransferQueue) SetProgress(item *TransferItem, bytes int64) {
+	q.mu.Lock()
+	item.TransferBytes = bytes
+	q.mu.Unlock()
+	q.notify()
+}
+
+// Finish marks an external transfer as done, failed when err is set
+func (q *TransferQueue) Finish(item *TransferItem, err error) {
+	q.mu.Lock()
+	switch {
+	case item.Status == StatusCancelled:
+	case err != nil:
+		item.Status = StatusFailed
+		item.Error = err
+		item.ErrorMsg = err.Error()
+	default:
+		item.Status = StatusCompleted
+	}
+	item.EndTime = time.Now().Format(time.RFC3339)
+	q.mu.Unlock()
+	q.notify()
+}
+
+// Cancelled is closed when the user cancels the transfer
+func (t *TransferItem) Cancelled() <-chan struct{} {
+	return t.cancel
+}
+
 // CancelItem cancels a specific transfer
 func (q *TransferQueue) CancelItem(id int) {
 	q.mu.Lock()
-	defer q.mu.Unlock()
 
 	for _, item := range q.items {
 		if item.ID == id {
@@ -224,13 +288,14 @@ func (q *TransferQueue) CancelItem(id int) {
 			break
 		}
 	}
+	q.mu.Unlock()
+
 	q.notify()
 }
 
 // ClearCompleted removes completed/failed/cancelled items
 func (q *TransferQueue) ClearCompleted() {
 	q.mu.Lock()
-	defer q.mu.Unlock()
 
 	var remaining []*TransferItem
 	for _, item := range q.items {
@@ -239,6 +304,8 @@ func (q *TransferQueue) ClearCompleted() {
 		}
 	}
 	q.items = remaining
+	q.mu.Unlock()
+
 	q.notify()
 }
 
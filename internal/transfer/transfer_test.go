@@ -2,6 +2,7 @@ package transfer
 
 import (
 	"testing"
+	"time"
 )
 
 func TestQueueState(t *testing.T) {
@@ -33,3 +34,39 @@ func TestQueueState(t *testing.T) {
 		t.Errorf("Expected cancelled, got %s", item.Status)
 	}
 }
+
+func TestQueueNotifiesOutsideLock(t *testing.T) {
+	q := NewTransferQueue(nil, 2)
+
+	// The change callback reads the queue back, as the app's does
+	var changes int
+	q.SetOnChange(func() {
+		q.GetItems()
+		q.GetStats()
+		changes++
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		up := q.AddUpload("local", "remote")
+		q.AddDownload("remote", "local")
+		ext := q.AddExternal(Download, "file", "remote", "local", 10)
+		q.SetProgress(ext, 5)
+		q.Finish(ext, nil)
+		q.CancelItem(up.ID)
+		q.ClearCompleted()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Queue deadlocked notifying a change")
+	}
+	if changes != 7 {
+		t.Errorf("%d changes notified, want 7", changes)
+	}
+	if items := q.GetItems(); len(items) != 1 || items[0].Direction != Download || items[0].Status != StatusPending {
+		t.Errorf("Items left %+v", items)
+	}
+}
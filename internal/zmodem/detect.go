@@ -0,0 +1,52 @@
+package zmodem
+
+import "bytes"
+
+// Direction says which way files go, seen from this side
+type Direction int
+
+const (
+	// Receive means the remote ran sz and is offering files
+	Receive Direction = iota
+	// Send means the remote ran rz and is waiting for files
+	Send
+)
+
+// Start sequences: the hex ZRQINIT header sz opens with and the hex
+// ZRINIT header rz opens with
+var (
+	startReceive = []byte{zpad, zpad, zdle, zhex, '0', '0'}
+	startSend    = []byte{zpad, zpad, zdle, zhex, '0', '1'}
+)
+
+// Detector watches terminal output for a ZMODEM session starting. It keeps
+// the end of the previous write so start sequences split across writes are found.
+type Detector struct {
+	tail []byte
+}
+
+// Scan looks for a start sequence in p. When found, start is the offset in p
+// where the transfer takes over, and prefix holds the part of the sequence
+// that arrived with earlier writes.
+func (d *Detector) Scan(p []byte) (start int, prefix []byte, dir Direction, found bool) {
+	data := append(d.tail, p...)
+
+	idx, dir := bytes.Index(data, startReceive), Receive
+	if i := bytes.Index(data, startSend); i >= 0 && (idx < 0 || i < idx) {
+		idx, dir = i, Send
+	}
+
+	if idx < 0 {
+		keep := min(len(data), len(startReceive)-1)
+		d.tail = append(d.tail[:0], data[len(data)-keep:]...)
+		return 0, nil, 0, false
+	}
+
+	d.tail = d.tail[:0]
+	start = idx - (len(data) - len(p))
+	if start < 0 {
+		prefix = append([]byte(nil), data[idx:len(data)-len(p)]...)
+		start = 0
+	}
+	return start, prefix, dir, true
+}
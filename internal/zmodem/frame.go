@@ -0,0 +1,387 @@
+package zmodem
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"time"
+)
+
+// Protocol characters
+const (
+	zpad = '*'
+	zdle = 0x18 // also CAN; five in a row abort the session
+	xon  = 0x11
+	xoff = 0x13
+)
+
+// Header encodings following ZPAD ZDLE
+const (
+	zbin   = 'A' // binary, CRC-16
+	zhex   = 'B' // hex, CRC-16
+	zbin32 = 'C' // binary, CRC-32
+)
+
+// Frame types
+const (
+	zRQINIT  = 0
+	zRINIT   = 1
+	zSINIT   = 2
+	zACK     = 3
+	zFILE    = 4
+	zSKIP    = 5
+	zNAK     = 6
+	zABORT   = 7
+	zFIN     = 8
+	zRPOS    = 9
+	zDATA    = 10
+	zEOF     = 11
+	zFERR    = 12
+	zCRC     = 13
+	zCOMPL   = 15
+	zCAN     = 16
+	zFREECNT = 17
+	zCOMMAND = 18
+)
+
+// Data subpacket ends
+const (
+	zCRCE = 'h' // frame ends, header follows
+	zCRCG = 'i' // frame continues nonstop
+	zCRCQ = 'j' // frame continues, ZACK expected
+	zCRCW = 'k' // frame ends, ZACK expected
+)
+
+// ZRINIT capability flags in ZF0
+const (
+	canFDX  = 0x01
+	canOVIO = 0x02
+	canFC32 = 0x20
+)
+
+// zcbin asks the receiver not to convert a file in ZFILE's ZF0
+const zcbin = 1
+
+// abortSequence is what lrzsz sends to cancel a transfer
+var abortSequence = []byte{24, 24, 24, 24, 24, 24, 24, 24, 24, 24, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8}
+
+var (
+	// ErrCancelled is returned when either side cancels the transfer
+	ErrCancelled = errors.New("transfer cancelled")
+	// ErrTimeout is returned when the other side stops responding
+	ErrTimeout = errors.New("timed out waiting for the remote side")
+
+	errBadCRC = errors.New("bad CRC")
+)
+
+// header is a frame header. The four data bytes hold either a file
+// position (little endian) or flags, with ZF0 in the last byte.
+type header struct {
+	typ  byte
+	data [4]byte
+}
+
+func posHeader(typ byte, pos int64) header {
+	h := header{typ: typ}
+	binary.LittleEndian.PutUint32(h.data[:], uint32(pos))
+	return h
+}
+
+func (h header) pos() int64 {
+	return int64(binary.LittleEndian.Uint32(h.data[:]))
+}
+
+func (h header) zf0() byte {
+	return h.data[3]
+}
+
+func (h header) bytes() []byte {
+	return []byte{h.typ, h.data[0], h.data[1], h.data[2], h.data[3]}
+}
+
+// crc16 is the CCITT CRC used by XMODEM and ZMODEM
+func crc16(crc uint16, p []byte) uint16 {
+	for _, b := range p {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// escape appends b to buf, ZDLE-escaping it if needed. All control
+// characters are escaped so nothing in between can act on them.
+func escape(buf []byte, b byte) []byte {
+	switch {
+	case b == 0x7f:
+		return append(buf, zdle, 'l')
+	case b == 0xff:
+		return append(buf, zdle, 'm')
+	case b&0x60 == 0:
+		return append(buf, zdle, b^0x40)
+	}
+	return append(buf, b)
+}
+
+func escapeAll(buf []byte, p []byte) []byte {
+	for _, b := range p {
+		buf = escape(buf, b)
+	}
+	return buf
+}
+
+// hexHeader encodes a header readable over links that aren't 8-bit clean
+func hexHeader(h header) []byte {
+	raw := h.bytes()
+	crc := crc16(0, raw)
+	raw = append(raw, byte(crc>>8), byte(crc))
+
+	buf := []byte{zpad, zpad, zdle, zhex}
+	buf = fmt.Appendf(buf, "%x", raw)
+	buf = append(buf, '\r', '\n'|0x80)
+	if h.typ != zFIN && h.typ != zACK {
+		buf = append(buf, xon)
+	}
+	return buf
+}
+
+// binHeader encodes a binary header with a 16 or 32-bit CRC
+func binHeader(h header, use32 bool) []byte {
+	raw := h.bytes()
+	if use32 {
+		buf := escapeAll([]byte{zpad, zdle, zbin32}, raw)
+		return escapeAll(buf, binary.LittleEndian.AppendUint32(nil, crc32.ChecksumIEEE(raw)))
+	}
+	crc := crc16(0, raw)
+	buf := escapeAll([]byte{zpad, zdle, zbin}, raw)
+	return escapeAll(buf, []byte{byte(crc >> 8), byte(crc)})
+}
+
+// subpacket encodes a data subpacket ending with end
+func subpacket(buf, data []byte, end byte, use32 bool) []byte {
+	buf = escapeAll(buf, data)
+	buf = append(buf, zdle, end)
+	if use32 {
+		crc := crc32.Update(crc32.ChecksumIEEE(data), crc32.IEEETable, []byte{end})
+		return escapeAll(buf, binary.LittleEndian.AppendUint32(nil, crc))
+	}
+	crc := crc16(crc16(0, data), []byte{end})
+	return escapeAll(buf, []byte{byte(crc >> 8), byte(crc)})
+}
+
+// readHeader skips input until a valid header arrives
+func (s *Session) readHeader(timeout time.Duration) (header, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		b, err := s.in.readByte(deadline)
+		if err != nil {
+			return header{}, err
+		}
+		if b == zdle {
+			if err := s.countCancel(); err != nil {
+				return header{}, err
+			}
+			continue
+		}
+		s.cans = 0
+		if b != zpad {
+			continue
+		}
+
+		// Any number of ZPADs, then ZDLE and the encoding
+		for b == zpad {
+			if b, err = s.in.readByte(deadline); err != nil {
+				return header{}, err
+			}
+		}
+		if b != zdle {
+			continue
+		}
+		if b, err = s.in.readByte(deadline); err != nil {
+			return header{}, err
+		}
+
+		var h header
+		switch b {
+		case zhex:
+			h, err = s.readHexHeader(deadline)
+		case zbin:
+			h, err = s.readBinHeader(deadline, false)
+		case zbin32:
+			h, err = s.readBinHeader(deadline, true)
+		default:
+			continue
+		}
+		if errors.Is(err, errBadCRC) {
+			continue
+		}
+		return h, err
+	}
+}
+
+// countCancel tracks consecutive CANs outside of escapes
+func (s *Session) countCancel() error {
+	s.cans++
+	if s.cans >= 5 {
+		return ErrCancelled
+	}
+	return nil
+}
+
+func (s *Session) readHexHeader(deadline time.Time) (header, error) {
+	var raw [7]byte
+	for i := range raw {
+		var v byte
+		for j := 0; j < 2; j++ {
+			b, err := s.in.readByte(deadline)
+			if err != nil {
+				return header{}, err
+			}
+			switch {
+			case b >= '0' && b <= '9':
+				v = v<<4 | (b - '0')
+			case b >= 'a' && b <= 'f':
+				v = v<<4 | (b - 'a' + 10)
+			case b >= 'A' && b <= 'F':
+				v = v<<4 | (b - 'A' + 10)
+			default:
+				return header{}, errBadCRC
+			}
+		}
+		raw[i] = v
+	}
+	if crc16(0, raw[:]) != 0 {
+		return header{}, errBadCRC
+	}
+	s.use32 = false
+	return header{typ: raw[0], data: [4]byte{raw[1], raw[2], raw[3], raw[4]}}, nil
+}
+
+func (s *Session) readBinHeader(deadline time.Time, use32 bool) (header, error) {
+	n := 7
+	if use32 {
+		n = 9
+	}
+	raw := make([]byte, n)
+	for i := range raw {
+		b, end, err := s.readEscaped(deadline)
+		if err != nil {
+			return header{}, err
+		}
+		if end {
+			return header{}, errBadCRC
+		}
+		raw[i] = b
+	}
+
+	if use32 {
+		if crc32.ChecksumIEEE(raw[:5]) != binary.LittleEndian.Uint32(raw[5:]) {
+			return header{}, errBadCRC
+		}
+	} else if crc16(0, raw) != 0 {
+		return header{}, errBadCRC
+	}
+	// Data subpackets after this header use the same CRC
+	s.use32 = use32
+	return header{typ: raw[0], data: [4]byte{raw[1], raw[2], raw[3], raw[4]}}, nil
+}
+
+// readEscaped reads one byte, undoing ZDLE escapes. end is set when the
+// byte is a subpacket end marker rather than data.
+func (s *Session) readEscaped(deadline time.Time) (b byte, end bool, err error) {
+	for {
+		b, err = s.in.readByte(deadline)
+		if err != nil {
+			return 0, false, err
+		}
+		switch b {
+		case xon, xoff, xon | 0x80, xoff | 0x80:
+			continue
+		case zdle:
+		default:
+			return b, false, nil
+		}
+
+		// Escaped byte
+		s.cans = 1
+		for {
+			if b, err = s.in.readByte(deadline); err != nil {
+				return 0, false, err
+			}
+			switch b {
+			case xon, xoff, xon | 0x80, xoff | 0x80:
+				continue
+			case zdle:
+				if err := s.countCancel(); err != nil {
+					return 0, false, err
+				}
+				continue
+			}
+			break
+		}
+		s.cans = 0
+
+		switch {
+		case b == zCRCE || b == zCRCG || b == zCRCQ || b == zCRCW:
+			return b, true, nil
+		case b == 'l':
+			return 0x7f, false, nil
+		case b == 'm':
+			return 0xff, false, nil
+		case b&0x60 == 0x40:
+			return b ^ 0x40, false, nil
+		}
+		return 0, false, errBadCRC
+	}
+}
+
+// readSubpacket reads a data subpacket and returns its data and end marker
+func (s *Session) readSubpacket(timeout time.Duration, max int) ([]byte, byte, error) {
+	deadline := time.Now().Add(timeout)
+	var data []byte
+	for {
+		b, end, err := s.readEscaped(deadline)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !end {
+			if len(data) >= max {
+				return nil, 0, errBadCRC
+			}
+			data = append(data, b)
+			continue
+		}
+
+		n := 2
+		if s.use32 {
+			n = 4
+		}
+		crc := make([]byte, n)
+		for i := range crc {
+			c, isEnd, err := s.readEscaped(deadline)
+			if err != nil {
+				return nil, 0, err
+			}
+			if isEnd {
+				return nil, 0, errBadCRC
+			}
+			crc[i] = c
+		}
+
+		if s.use32 {
+			want := crc32.Update(crc32.ChecksumIEEE(data), crc32.IEEETable, []byte{b})
+			if want != binary.LittleEndian.Uint32(crc) {
+				return nil, 0, errBadCRC
+			}
+		} else if crc16(crc16(0, data), []byte{b, crc[0], crc[1]}) != 0 {
+			return nil, 0, errBadCRC
+		}
+		return data, b, nil
+	}
+}
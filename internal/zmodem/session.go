@@ -0,0 +1,576 @@
+package zmodem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerTimeout = 10 * time.Second
+	retries       = 5
+	blockSize     = 1024
+	window        = 32 * 1024 // bytes sent before waiting for an acknowledgement
+	maxFileInfo   = 1024
+)
+
+// ErrSkip can be returned by Callbacks.Create to skip an incoming file
+var ErrSkip = errors.New("skip file")
+
+// File is one file of a transfer
+type File struct {
+	Name    string    // name on the remote side
+	Path    string    // local path
+	Size    int64     // -1 if the sender didn't say
+	ModTime time.Time // zero if the sender didn't say
+}
+
+// Callbacks connect a session to the local files and to progress reporting
+type Callbacks struct {
+	// Create opens the local file for an incoming one and sets f.Path
+	Create func(f *File) (io.WriteCloser, error)
+	// Choose picks local files when the remote is waiting for them. No
+	// files or an error cancels the transfer.
+	Choose func() ([]string, error)
+	// Start, Progress and Done follow each file; any may be nil
+	Start    func(f *File)
+	Progress func(f *File, bytes int64)
+	Done     func(f *File, err error)
+}
+
+// Session is one ZMODEM transfer running over a terminal's stream. Output
+// from the remote side is passed in with Feed; protocol replies go to out.
+type Session struct {
+	in    *stream
+	out   io.Writer
+	cb    Callbacks
+	use32 bool // CRC-32 for the current frame
+	cans  int  // consecutive CANs seen
+}
+
+// NewSession creates a session writing protocol replies to out
+func NewSession(out io.Writer, cb Callbacks) *Session {
+	return &Session{in: newStream(), out: out, cb: cb}
+}
+
+// Feed passes output from the remote side to the session. It returns false
+// once the session has finished; the output then belongs to the terminal again.
+func (s *Session) Feed(p []byte) bool {
+	return s.in.write(p)
+}
+
+// Leftover returns output that arrived after the transfer ended. It is
+// only returned once.
+func (s *Session) Leftover() []byte {
+	return s.in.leftover()
+}
+
+// Cancel stops the transfer; Run returns ErrCancelled
+func (s *Session) Cancel() {
+	s.in.cancel()
+}
+
+// Run carries out the transfer and returns when it is over
+func (s *Session) Run(dir Direction) error {
+	var err error
+	if dir == Receive {
+		err = s.receive()
+	} else {
+		err = s.send()
+	}
+
+	if err != nil {
+		// Make sure the remote side gives up too
+		s.out.Write(abortSequence)
+		if errors.Is(err, errStreamCancelled) {
+			err = ErrCancelled
+		}
+	}
+	s.in.finish()
+	return err
+}
+
+func (s *Session) write(p []byte) error {
+	_, err := s.out.Write(p)
+	return err
+}
+
+func (s *Session) sendHex(h header) error {
+	return s.write(hexHeader(h))
+}
+
+func (s *Session) fileStart(f *File) {
+	if s.cb.Start != nil {
+		s.cb.Start(f)
+	}
+}
+
+func (s *Session) fileProgress(f *File, n int64) {
+	if s.cb.Progress != nil {
+		s.cb.Progress(f, n)
+	}
+}
+
+func (s *Session) fileDone(f *File, err error) {
+	if s.cb.Done != nil {
+		s.cb.Done(f, err)
+	}
+}
+
+// receive takes files from a remote sz
+func (s *Session) receive() error {
+	// The stream starts with sz's ZRQINIT, which the loop answers
+	rinit := header{typ: zRINIT, data: [4]byte{0, 0, 0, canFDX | canOVIO | canFC32}}
+	for tries := 0; ; {
+		h, err := s.readHeader(headerTimeout)
+		if errors.Is(err, ErrTimeout) {
+			if tries++; tries > retries {
+				return err
+			}
+			s.sendHex(rinit)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		tries = 0
+
+		switch h.typ {
+		case zRQINIT, zEOF, zDATA:
+			s.sendHex(rinit)
+
+		case zSINIT:
+			// The attention string is only needed to interrupt a sender, which we never do
+			if _, _, err := s.readSubpacket(headerTimeout, maxFileInfo); err != nil {
+				s.sendHex(header{typ: zNAK})
+				continue
+			}
+			s.sendHex(posHeader(zACK, 1))
+
+		case zFREECNT:
+			s.sendHex(posHeader(zACK, 0))
+
+		case zCOMMAND:
+			// Never run commands on behalf of the remote side
+			s.readSubpacket(headerTimeout, maxFileInfo)
+			s.sendHex(posHeader(zCOMPL, 1))
+
+		case zFILE:
+			info, _, err := s.readSubpacket(headerTimeout, maxFileInfo)
+			if err != nil {
+				s.sendHex(header{typ: zNAK})
+				continue
+			}
+			if err := s.receiveFile(parseFileInfo(info)); err != nil {
+				return err
+			}
+			s.sendHex(rinit)
+
+		case zFIN:
+			s.sendHex(header{typ: zFIN})
+			// The sender signs off with "OO", which shouldn't reach the terminal
+			s.in.discardPrefix([]byte("OO"), time.Now().Add(time.Second))
+			s.in.discardPrefix(nil, time.Now())
+			return nil
+
+		case zCAN, zABORT:
+			return ErrCancelled
+		}
+	}
+}
+
+// parseFileInfo decodes the ZFILE subpacket: name NUL size mtime mode ...
+func parseFileInfo(info []byte) *File {
+	name, rest, _ := strings.Cut(string(info), "\x00")
+	rest, _, _ = strings.Cut(rest, "\x00")
+
+	f := &File{Name: name, Size: -1}
+	var size, mtime int64
+	if n, _ := fmt.Sscanf(rest, "%d %o", &size, &mtime); n >= 1 {
+		f.Size = size
+		if n == 2 && mtime > 0 {
+			f.ModTime = time.Unix(mtime, 0)
+		}
+	}
+	return f
+}
+
+// receiveFile takes one file after its ZFILE header
+func (s *Session) receiveFile(f *File) error {
+	w, err := s.cb.Create(f)
+	if errors.Is(err, ErrSkip) {
+		return s.sendHex(header{typ: zSKIP})
+	}
+	if err != nil {
+		s.sendHex(header{typ: zSKIP})
+		s.fileStart(f)
+		s.fileDone(f, err)
+		return nil
+	}
+
+	s.fileStart(f)
+	var offset int64
+	err = s.receiveData(f, w, &offset)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && !f.ModTime.IsZero() {
+		os.Chtimes(f.Path, f.ModTime, f.ModTime)
+	}
+	s.fileDone(f, err)
+	return err
+}
+
+func (s *Session) receiveData(f *File, w io.Writer, offset *int64) error {
+	s.sendHex(posHeader(zRPOS, 0))
+
+	for tries := 0; ; {
+		h, err := s.readHeader(headerTimeout)
+		if errors.Is(err, ErrTimeout) {
+			if tries++; tries > retries {
+				return err
+			}
+			s.sendHex(posHeader(zRPOS, *offset))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		tries = 0
+
+		switch h.typ {
+		case zDATA:
+			if h.pos() != *offset {
+				s.sendHex(posHeader(zRPOS, *offset))
+				continue
+			}
+			if err := s.receiveFrame(f, w, offset); err != nil {
+				if !errors.Is(err, errBadCRC) {
+					return err
+				}
+				s.sendHex(posHeader(zRPOS, *offset))
+			}
+
+		case zEOF:
+			// An early ZEOF means data went missing; the sender will retry
+			if h.pos() == *offset {
+				return nil
+			}
+
+		case zFILE:
+			// Our ZRPOS got lost
+			s.readSubpacket(headerTimeout, maxFileInfo)
+			s.sendHex(posHeader(zRPOS, *offset))
+
+		case zNAK:
+			s.sendHex(posHeader(zRPOS, *offset))
+
+		case zSKIP, zFIN, zCAN, zABORT:
+			return ErrCancelled
+		}
+	}
+}
+
+// receiveFrame reads the data subpackets following a ZDATA header
+func (s *Session) receiveFrame(f *File, w io.Writer, offset *int64) error {
+	for {
+		data, end, err := s.readSubpacket(headerTimeout, 8*blockSize)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		*offset += int64(len(data))
+		s.fileProgress(f, *offset)
+
+		switch end {
+		case zCRCW:
+			return s.sendHex(posHeader(zACK, *offset))
+		case zCRCQ:
+			s.sendHex(posHeader(zACK, *offset))
+		case zCRCE:
+			return nil
+		}
+	}
+}
+
+// send gives files to a remote rz
+func (s *Session) send() error {
+	h, err := s.readHeader(headerTimeout)
+	if err != nil {
+		return err
+	}
+	if h.typ != zRINIT {
+		return fmt.Errorf("unexpected frame %d", h.typ)
+	}
+	use32 := h.zf0()&canFC32 != 0
+
+	paths, err := s.cb.Choose()
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return ErrCancelled
+	}
+
+	var total int64
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil {
+			total += info.Size()
+		}
+	}
+	for i, p := range paths {
+		info, _ := os.Stat(p)
+		left := total
+		if info != nil {
+			left -= info.Size()
+		}
+		if err := s.sendFile(p, use32, len(paths)-i, left); err != nil {
+			return err
+		}
+		total = left
+	}
+
+	for tries := 0; tries <= retries; tries++ {
+		s.sendHex(header{typ: zFIN})
+		h, err := s.readHeader(headerTimeout)
+		if errors.Is(err, ErrTimeout) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if h.typ == zFIN {
+			// Keep the end of the header off the terminal
+			s.in.discardPrefix(nil, time.Now())
+			return s.write([]byte("OO"))
+		}
+	}
+	return ErrTimeout
+}
+
+func (s *Session) sendFile(path string, use32 bool, filesLeft int, bytesLeft int64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	f := &File{Name: filepath.Base(path), Path: path, Size: info.Size(), ModTime: info.ModTime()}
+	s.fileStart(f)
+	err = s.sendFileData(f, file, use32, filesLeft, bytesLeft)
+	s.fileDone(f, err)
+	if errors.Is(err, ErrSkip) {
+		return nil
+	}
+	return err
+}
+
+func (s *Session) sendFileData(f *File, r io.ReaderAt, use32 bool, filesLeft int, bytesLeft int64) error {
+	fileInfo := fmt.Appendf([]byte(f.Name), "\x00%d %o %o 0 %d %d\x00",
+		f.Size, f.ModTime.Unix(), 0100644, filesLeft, bytesLeft+f.Size)
+	zfile := binHeader(header{typ: zFILE, data: [4]byte{0, 0, 0, zcbin}}, use32)
+	zfile = subpacket(zfile, fileInfo, zCRCW, use32)
+
+	// Offer the file until the receiver says where to start
+	var pos int64 = -1
+	for tries := 0; pos < 0; tries++ {
+		if tries > retries {
+			return ErrTimeout
+		}
+		if err := s.write(zfile); err != nil {
+			return err
+		}
+	wait:
+		h, err := s.readHeader(headerTimeout)
+		if errors.Is(err, ErrTimeout) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		switch h.typ {
+		case zRPOS:
+			pos = h.pos()
+		case zSKIP:
+			return ErrSkip
+		case zRINIT:
+			// Repeated while we were getting ready; the answer is still coming
+			goto wait
+		case zCAN, zABORT, zFIN:
+			return ErrCancelled
+		}
+	}
+
+	buf := make([]byte, blockSize)
+	for tries := 0; tries <= retries; {
+		if pos >= f.Size {
+			s.write(binHeader(posHeader(zEOF, f.Size), use32))
+		} else {
+			frame := binHeader(posHeader(zDATA, pos), use32)
+			sent := int64(0)
+			for sent < window && pos+sent < f.Size {
+				n, err := r.ReadAt(buf, pos+sent)
+				if n == 0 && err != nil {
+					return err
+				}
+				sent += int64(n)
+				end := byte(zCRCG)
+				if sent >= window || pos+sent >= f.Size {
+					end = zCRCW
+				}
+				frame = subpacket(frame, buf[:n], end, use32)
+			}
+			if err := s.write(frame); err != nil {
+				return err
+			}
+			pos += sent
+		}
+
+		h, err := s.readHeader(headerTimeout)
+		if errors.Is(err, ErrTimeout) {
+			tries++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		tries = 0
+
+		switch h.typ {
+		case zACK:
+			s.fileProgress(f, pos)
+		case zRPOS:
+			// The receiver lost data; go back to where it is
+			pos = h.pos()
+		case zRINIT:
+			if pos >= f.Size {
+				s.fileProgress(f, f.Size)
+				return nil
+			}
+		case zSKIP:
+			return ErrSkip
+		case zCAN, zABORT, zFIN:
+			return ErrCancelled
+		}
+	}
+	return ErrTimeout
+}
+
+// stream buffers output from the remote side for the protocol to read
+type stream struct {
+	mu        sync.Mutex
+	buf       []byte
+	signal    chan struct{} // closed and replaced on every write
+	done      bool          // the session finished
+	cancelled bool
+}
+
+var errStreamCancelled = errors.New("cancelled")
+
+func newStream() *stream {
+	return &stream{signal: make(chan struct{})}
+}
+
+func (st *stream) write(p []byte) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.done {
+		return false
+	}
+	st.buf = append(st.buf, p...)
+	close(st.signal)
+	st.signal = make(chan struct{})
+	return true
+}
+
+func (st *stream) readByte(deadline time.Time) (byte, error) {
+	for {
+		st.mu.Lock()
+		if st.cancelled {
+			st.mu.Unlock()
+			return 0, errStreamCancelled
+		}
+		if len(st.buf) > 0 {
+			b := st.buf[0]
+			st.buf = st.buf[1:]
+			st.mu.Unlock()
+			return b, nil
+		}
+		wait := st.signal
+		st.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(deadline))
+		select {
+		case <-wait:
+			timer.Stop()
+		case <-timer.C:
+			return 0, ErrTimeout
+		}
+	}
+}
+
+// discardPrefix drops p from the front of the stream if it shows up in time,
+// along with the line end and XON trailing hex headers
+func (st *stream) discardPrefix(p []byte, deadline time.Time) {
+	for {
+		st.mu.Lock()
+		for len(st.buf) > 0 && strings.IndexByte("\r\n\x8a\x8d\x11", st.buf[0]) >= 0 {
+			st.buf = st.buf[1:]
+		}
+		n := min(len(st.buf), len(p))
+		if string(st.buf[:n]) != string(p[:n]) {
+			st.mu.Unlock()
+			return
+		}
+		if n == len(p) {
+			st.buf = st.buf[n:]
+			st.mu.Unlock()
+			return
+		}
+		wait := st.signal
+		st.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(deadline))
+		select {
+		case <-wait:
+			timer.Stop()
+		case <-timer.C:
+			return
+		}
+	}
+}
+
+func (st *stream) cancel() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if !st.cancelled {
+		st.cancelled = true
+		close(st.signal)
+		st.signal = make(chan struct{})
+	}
+}
+
+func (st *stream) finish() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.done = true
+}
+
+func (st *stream) leftover() []byte {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	p := st.buf
+	st.buf = nil
+	return p
+}
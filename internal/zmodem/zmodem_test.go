@@ -0,0 +1,223 @@
+package zmodem
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCRC16(t *testing.T) {
+	if got := crc16(0, []byte("123456789")); got != 0x31c3 {
+		t.Errorf("crc16 = %#x", got)
+	}
+}
+
+func TestDetectorSplit(t *testing.T) {
+	var d Detector
+	if _, _, _, found := d.Scan([]byte("$ rz\r\nrz waiting to receive.**\x18")); found {
+		t.Fatal("Found a partial start sequence")
+	}
+	start, prefix, dir, found := d.Scan([]byte("B0100000023be50\r\x8a\x11"))
+	if !found || dir != Send || start != 0 || string(prefix) != "**\x18" {
+		t.Errorf("Scan = %d %q %v %v", start, prefix, dir, found)
+	}
+}
+
+// feeder delivers one session's output to the other side
+type feeder struct {
+	mu   sync.Mutex
+	peer *Session
+}
+
+func (f *feeder) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	peer := f.peer
+	f.mu.Unlock()
+	peer.Feed(append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func testFiles(t *testing.T) (dir string, paths []string) {
+	dir = t.TempDir()
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	contents := map[string][]byte{
+		"bytes.bin": bytes.Repeat(all, 400), // every byte value, over several windows
+		"empty.txt": nil,
+		"notes.txt": []byte("hello\r\n**\x18B00 not a header\n"),
+	}
+	for name, data := range contents {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+	return dir, paths
+}
+
+func saveTo(dir string) func(f *File) (io.WriteCloser, error) {
+	return func(f *File) (io.WriteCloser, error) {
+		f.Path = filepath.Join(dir, filepath.Base(f.Name))
+		return os.Create(f.Path)
+	}
+}
+
+func compareDirs(t *testing.T, want, got string, names []string) {
+	t.Helper()
+	for _, name := range names {
+		a, _ := os.ReadFile(filepath.Join(want, name))
+		b, err := os.ReadFile(filepath.Join(got, name))
+		if err != nil {
+			t.Errorf("%s not received: %v", name, err)
+		} else if !bytes.Equal(a, b) {
+			t.Errorf("%s differs: %d bytes, want %d", name, len(b), len(a))
+		}
+	}
+}
+
+func TestLoopback(t *testing.T) {
+	srcDir, paths := testFiles(t)
+	dstDir := t.TempDir()
+
+	toSender, toReceiver := &feeder{}, &feeder{}
+	var done []string
+	sender := NewSession(toReceiver, Callbacks{
+		Choose: func() ([]string, error) { return paths, nil },
+	})
+	receiver := NewSession(toSender, Callbacks{
+		Create: saveTo(dstDir),
+		Done: func(f *File, err error) {
+			if err != nil {
+				t.Errorf("%s: %v", f.Name, err)
+			}
+			done = append(done, f.Name)
+		},
+	})
+	toSender.peer, toReceiver.peer = sender, receiver
+
+	// The receiving side starts as if sz had announced itself
+	receiver.Feed(hexHeader(header{typ: zRQINIT}))
+
+	errs := make(chan error, 2)
+	go func() { errs <- sender.Run(Send) }()
+	go func() { errs <- receiver.Run(Receive) }()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("Transfer did not finish")
+		}
+	}
+
+	if len(done) != len(paths) {
+		t.Errorf("Finished files %q", done)
+	}
+	compareDirs(t, srcDir, dstDir, []string{"bytes.bin", "empty.txt", "notes.txt"})
+
+	// Output after the transfer goes back to the terminal
+	if sender.Feed([]byte("$ ")) || string(sender.Leftover()) != "" {
+		t.Error("Session took output after finishing")
+	}
+}
+
+// lookTool finds a program the interop tests need. lrzsz is installed as
+// lsz and lrz on some systems. Without it the tests skip, unless
+// GENPILOT_REQUIRE_LRZSZ is set so a build that installs lrzsz fails
+// rather than silently not running them.
+func lookTool(t *testing.T, names ...string) string {
+	t.Helper()
+	for _, name := range names {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	if os.Getenv("GENPILOT_REQUIRE_LRZSZ") != "" {
+		t.Fatalf("%s not installed", names[0])
+	}
+	t.Skipf("%s not installed; install lrzsz to run the interop tests", names[0])
+	return ""
+}
+
+// lrzsz runs sz or rz with args in a local PTY and connects a session to it
+func lrzsz(t *testing.T, dir, tool, args string, dirn Direction, cb Callbacks) {
+	t.Helper()
+	program := lookTool(t, tool, "l"+tool)
+	lookTool(t, "script")
+
+	cmd := exec.Command("script", "-qfec", program+" "+args, "/dev/null")
+	cmd.Dir = dir
+	stdin, _ := cmd.StdinPipe()
+	stdout, _ := cmd.StdoutPipe()
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+
+	s := NewSession(stdin, cb)
+	result := make(chan error, 1)
+	go func() {
+		var d Detector
+		buf := make([]byte, 32*1024)
+		started := false
+		for {
+			n, err := stdout.Read(buf)
+			if n > 0 {
+				if !started {
+					if start, prefix, _, found := d.Scan(buf[:n]); found {
+						started = true
+						s.Feed(append(prefix, buf[start:n]...))
+						go func() { result <- s.Run(dirn) }()
+					}
+				} else {
+					s.Feed(append([]byte(nil), buf[:n]...))
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(30 * time.Second):
+		cmd.Process.Kill()
+		t.Fatal("Transfer did not finish")
+	}
+}
+
+func TestReceiveFromSz(t *testing.T) {
+	srcDir, paths := testFiles(t)
+	dstDir := t.TempDir()
+
+	args := "-q"
+	for _, p := range paths {
+		args += " " + filepath.Base(p)
+	}
+	lrzsz(t, srcDir, "sz", args, Receive, Callbacks{Create: saveTo(dstDir)})
+	compareDirs(t, srcDir, dstDir, []string{"bytes.bin", "empty.txt", "notes.txt"})
+}
+
+func TestSendToRz(t *testing.T) {
+	srcDir, paths := testFiles(t)
+	dstDir := t.TempDir()
+
+	lrzsz(t, dstDir, "rz", "-q", Send, Callbacks{
+		Choose: func() ([]string, error) { return paths, nil },
+	})
+	compareDirs(t, srcDir, dstDir, []string{"bytes.bin", "empty.txt", "notes.txt"})
+}
@@ -0,0 +1,51 @@
+package main
+
+import "fmt"
+
+// CreateSymlink creates a symlink at linkPath on a session's host pointing
+// to target, which is stored as given and may be relative
+func (a *App) CreateSymlink(id, target, linkPath string) error {
+	a.sessionsLock.RLock()
+	s, ok := a.sessions[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok || s.SFTPClient == nil {
+		return fmt.Errorf("not connected for session %s", id)
+	}
+	if target == "" || linkPath == "" {
+		return fmt.Errorf("symlink target and path required")
+	}
+	return s.SFTPClient.Symlink(target, linkPath)
+}
+
+// CreateHardLink creates newPath on a session's host as another name for
+// the file at oldPath
+func (a *App) CreateHardLink(id, oldPath, newPath string) error {
+	a.sessionsLock.RLock()
+	s, ok := a.sessions[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok || s.SFTPClient == nil {
+		return fmt.Errorf("not connected for session %s", id)
+	}
+	if oldPath == "" || newPath == "" {
+		return fmt.Errorf("hard link source and path required")
+	}
+	return s.SFTPClient.Link(oldPath, newPath)
+}
+
+// RealPath resolves a path on a session's host to an absolute one, with
+// symlinks followed
+func (a *App) RealPath(id, path string) (string, error) {
+	a.sessionsLock.RLock()
+	s, ok := a.sessions[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok || s.SFTPClient == nil {
+		return "", fmt.Errorf("not connected for session %s", id)
+	}
+	if path == "" {
+		path = "."
+	}
+	return s.SFTPClient.RealPath(path)
+}
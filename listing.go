@@ -0,0 +1,103 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ListOptions sort and filter a directory listing. The zero value lists
+// everything by name with directories first.
+type ListOptions struct {
+	SortBy     string `json:"sort_by"` // name, size, time, type, owner or perm; name if empty
+	Desc       bool   `json:"desc"`
+	MixDirs    bool   `json:"mix_dirs"`    // sort directories among files
+	Filter     string `json:"filter"`      // glob or substring on names, case-insensitive
+	HideHidden bool   `json:"hide_hidden"` // leave out dot files and hidden files
+}
+
+// listFiles applies opts to a listing
+func listFiles(files []FileItem, opts ListOptions) ([]FileItem, error) {
+	match, err := nameMatcher(opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := files[:0]
+	for _, f := range files {
+		if (opts.HideHidden && f.Hidden) || !match(f.Name) {
+			continue
+		}
+		kept = append(kept, f)
+	}
+
+	compare, err := fileComparer(opts.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(kept, func(a, b FileItem) int {
+		if !opts.MixDirs && a.IsDir != b.IsDir {
+			if a.IsDir {
+				return -1
+			}
+			return 1
+		}
+		c := compare(a, b)
+		if c == 0 {
+			c = strings.Compare(a.Name, b.Name)
+		}
+		if opts.Desc {
+			return -c
+		}
+		return c
+	})
+	return kept, nil
+}
+
+// nameMatcher matches names against a glob, or a substring when the filter
+// has no wildcards
+func nameMatcher(filter string) (func(string) bool, error) {
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	if filter == "" {
+		return func(string) bool { return true }, nil
+	}
+	if !strings.ContainsAny(filter, "*?[") {
+		return func(name string) bool {
+			return strings.Contains(strings.ToLower(name), filter)
+		}, nil
+	}
+	if _, err := path.Match(filter, ""); err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %w", filter, err)
+	}
+	return func(name string) bool {
+		ok, _ := path.Match(filter, strings.ToLower(name))
+		return ok
+	}, nil
+}
+
+func fileComparer(sortBy string) (func(a, b FileItem) int, error) {
+	switch sortBy {
+	case "", "name":
+		return func(a, b FileItem) int {
+			return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		}, nil
+	case "size":
+		return func(a, b FileItem) int { return cmp.Compare(a.Bytes, b.Bytes) }, nil
+	case "time":
+		return func(a, b FileItem) int { return cmp.Compare(a.MTime, b.MTime) }, nil
+	case "type":
+		return func(a, b FileItem) int {
+			return cmp.Compare(strings.ToLower(path.Ext(a.Name)), strings.ToLower(path.Ext(b.Name)))
+		}, nil
+	case "owner":
+		return func(a, b FileItem) int {
+			return cmp.Or(cmp.Compare(a.Owner, b.Owner), cmp.Compare(a.UID, b.UID))
+		}, nil
+	case "perm":
+		return func(a, b FileItem) int { return cmp.Compare(a.Perm, b.Perm) }, nil
+	default:
+		return nil, fmt.Errorf("unknown sort %q", sortBy)
+	}
+}
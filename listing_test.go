@@ -0,0 +1,106 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func testListing() []FileItem {
+	return []FileItem{
+		{Name: "notes.txt", Bytes: 300, MTime: 30, Perm: "0644", Owner: "bob", UID: 1001},
+		{Name: "src", IsDir: true, Bytes: 4096, MTime: 10, Perm: "0755", Owner: "alice", UID: 1000},
+		{Name: ".profile", Bytes: 100, MTime: 20, Perm: "0600", Owner: "alice", UID: 1000, Hidden: true},
+		{Name: "Build.log", Bytes: 200, MTime: 40, Perm: "0644", Owner: "alice", UID: 1000},
+		{Name: "docs", IsDir: true, Bytes: 4096, MTime: 50, Perm: "0700", Owner: "bob", UID: 1001},
+	}
+}
+
+func names(files []FileItem) []string {
+	var list []string
+	for _, f := range files {
+		list = append(list, f.Name)
+	}
+	return list
+}
+
+func TestListFilesSort(t *testing.T) {
+	tests := []struct {
+		opts ListOptions
+		want []string
+	}{
+		{ListOptions{}, []string{"docs", "src", ".profile", "Build.log", "notes.txt"}},
+		// Descending order still lists directories first
+		{ListOptions{Desc: true}, []string{"src", "docs", "notes.txt", "Build.log", ".profile"}},
+		{ListOptions{SortBy: "size"}, []string{"docs", "src", ".profile", "Build.log", "notes.txt"}},
+		{ListOptions{SortBy: "time", Desc: true}, []string{"docs", "src", "Build.log", "notes.txt", ".profile"}},
+		{ListOptions{SortBy: "time", MixDirs: true}, []string{"src", ".profile", "notes.txt", "Build.log", "docs"}},
+		{ListOptions{SortBy: "type", MixDirs: true}, []string{"docs", "src", "Build.log", ".profile", "notes.txt"}},
+		{ListOptions{SortBy: "owner"}, []string{"src", "docs", ".profile", "Build.log", "notes.txt"}},
+		{ListOptions{SortBy: "perm", MixDirs: true, Desc: true}, []string{"src", "docs", "notes.txt", "Build.log", ".profile"}},
+	}
+	for _, tt := range tests {
+		got, err := listFiles(testListing(), tt.opts)
+		if err != nil {
+			t.Errorf("%+v: %v", tt.opts, err)
+			continue
+		}
+		if !slices.Equal(names(got), tt.want) {
+			t.Errorf("%+v: got %q, want %q", tt.opts, names(got), tt.want)
+		}
+	}
+
+	if _, err := listFiles(testListing(), ListOptions{SortBy: "colour"}); err == nil {
+		t.Error("Unknown sort accepted")
+	}
+}
+
+func TestListFilesFilter(t *testing.T) {
+	tests := []struct {
+		opts ListOptions
+		want []string
+	}{
+		{ListOptions{HideHidden: true}, []string{"docs", "src", "Build.log", "notes.txt"}},
+		// Without wildcards a filter matches anywhere in the name
+		{ListOptions{Filter: "O"}, []string{"docs", ".profile", "Build.log", "notes.txt"}},
+		// With them it has to match the whole name
+		{ListOptions{Filter: "*.LOG"}, []string{"Build.log"}},
+		{ListOptions{Filter: "?o*"}, []string{"docs", "notes.txt"}},
+		{ListOptions{Filter: "[bn]*"}, []string{"Build.log", "notes.txt"}},
+		{ListOptions{Filter: "*o*", HideHidden: true}, []string{"docs", "Build.log", "notes.txt"}},
+		{ListOptions{Filter: "  "}, []string{"docs", "src", ".profile", "Build.log", "notes.txt"}},
+	}
+	for _, tt := range tests {
+		got, err := listFiles(testListing(), tt.opts)
+		if err != nil {
+			t.Errorf("%+v: %v", tt.opts, err)
+			continue
+		}
+		if !slices.Equal(names(got), tt.want) {
+			t.Errorf("%+v: got %q, want %q", tt.opts, names(got), tt.want)
+		}
+	}
+}
+
+func TestNameMatcherInvalid(t *testing.T) {
+	for _, filter := range []string{"[", "a[b", "*[z-"} {
+		if _, err := nameMatcher(filter); err == nil {
+			t.Errorf("nameMatcher(%q) accepted", filter)
+		}
+	}
+	if _, err := listFiles(testListing(), ListOptions{Filter: "[x"}); err == nil {
+		t.Error("listFiles accepted an invalid filter")
+	}
+}
+
+func TestFileComparerTies(t *testing.T) {
+	// Equal keys fall back to the name so the order doesn't depend on the
+	// server's listing
+	files := []FileItem{{Name: "b", Bytes: 1}, {Name: "a", Bytes: 1}, {Name: "c", Bytes: 0}}
+	got, err := listFiles(files, ListOptions{SortBy: "size"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"c", "a", "b"}; !slices.Equal(names(got), want) {
+		t.Errorf("got %q, want %q", names(got), want)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os/user"
+	"strings"
+
+	"Genpilot/internal/cmdline"
+	"Genpilot/internal/config"
+	"Genpilot/internal/localshell"
+	sshclient "Genpilot/internal/ssh"
+)
+
+// startLocalShell starts a shell on this machine in a pseudo-terminal
+func startLocalShell(cfg config.Session, rows, cols int) (*startedShell, error) {
+	opts := localshell.Options{Rows: rows, Cols: cols}
+	if cfg.Local != nil {
+		opts.Command = cfg.Local.Command
+		opts.Dir = cfg.Local.Dir
+		opts.Env = cfg.Local.Env
+	}
+
+	sh, err := localshell.Start(opts)
+	if err != nil {
+		return nil, fmt.Errorf("local shell failed: %w", err)
+	}
+
+	return &startedShell{
+		process: sh,
+		stdin:   sh,
+		outputs: []io.Reader{sh},
+		wait: func() (sshclient.ExitStatus, error) {
+			status, err := sh.Wait()
+			return sshclient.ExitStatus{Code: status.Code, Signal: status.Signal}, err
+		},
+	}, nil
+}
+
+// ConnectLocal opens a local shell session. Settings come from the saved
+// session called name if there is one; otherwise the user's shell starts
+// in the home directory.
+func (a *App) ConnectLocal(id, name string) (string, error) {
+	var cfg config.Session
+	if a.sessionMgr != nil {
+		cfg, _ = a.sessionMgr.FindSession(name)
+	}
+	cfg.Protocol = config.ProtocolLocal
+
+	username := ""
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+
+	if err := a.startShellSession(id, name, "localhost", username, cfg, nil); err != nil {
+		return "", err
+	}
+	return "Connected", nil
+}
+
+// SaveLocalSession saves a local shell session. An empty command runs the
+// user's shell and an empty dir starts in the home directory; env holds
+// KEY=VALUE pairs added to the environment.
+func (a *App) SaveLocalSession(name, group, command, dir string, env []string) error {
+	if strings.TrimSpace(command) != "" {
+		if _, err := cmdline.Split(command); err != nil {
+			return err
+		}
+	}
+	for _, e := range env {
+		if k, _, ok := strings.Cut(e, "="); !ok || k == "" {
+			return fmt.Errorf("environment variable %q is not KEY=VALUE", e)
+		}
+	}
+
+	// Keep settings that aren't edited through this form
+	session, _ := a.sessionMgr.FindSession(name)
+	session.Name = name
+	session.Group = group
+	session.Protocol = config.ProtocolLocal
+	session.Local = &config.LocalShell{Command: command, Dir: dir, Env: env}
+	return a.sessionMgr.AddSession(session)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"Genpilot/internal/config"
+	"Genpilot/internal/mux"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ListMultiplexerSessions lists the tmux and screen sessions on a
+// session's host. Hosts without either tool have none.
+func (a *App) ListMultiplexerSessions(id string) ([]mux.Session, error) {
+	a.sessionsLock.RLock()
+	s, ok := a.sessions[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok || s.SSHClient == nil {
+		return nil, fmt.Errorf("session %s not connected", id)
+	}
+
+	// Both exit non-zero when there is nothing to list, so only output counts
+	var out bytes.Buffer
+	if _, err := s.SSHClient.ExecCommand(mux.ListTmux, &out, io.Discard); err != nil {
+		return nil, err
+	}
+	sessions := mux.ParseTmux(out.String())
+
+	out.Reset()
+	if _, err := s.SSHClient.ExecCommand(mux.ListScreen, &out, io.Discard); err != nil {
+		return nil, err
+	}
+	return append(sessions, mux.ParseScreen(out.String())...), nil
+}
+
+// announceMultiplexers emits multiplexers-<id> with the host's tmux and
+// screen sessions if it has any
+func (a *App) announceMultiplexers(state *SessionState) {
+	sessions, err := a.ListMultiplexerSessions(state.ID)
+	if err != nil {
+		runtime.LogWarning(a.ctx, "Listing tmux and screen sessions for "+state.ID+": "+err.Error())
+		return
+	}
+	if len(sessions) > 0 && a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "multiplexers-"+state.ID, sessions)
+	}
+}
+
+// AttachMultiplexer attaches a terminal to a tmux or screen session by
+// typing the command into its shell, creating the session if needed
+func (a *App) AttachMultiplexer(id, tool, name string) error {
+	if tool != mux.Tmux && tool != mux.Screen {
+		return fmt.Errorf("unknown multiplexer %q", tool)
+	}
+	if name == "" {
+		return fmt.Errorf("multiplexer session name required")
+	}
+
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	var state *SessionState
+	if ok {
+		state = a.sessions[ch.SessionID]
+	}
+	a.sessionsLock.RUnlock()
+	if !ok || state == nil {
+		return fmt.Errorf("terminal %s not connected", id)
+	}
+
+	a.WriteToTerminal(id, mux.AttachCommand(tool, name)+"\r")
+	// A session created just now starts a shell without the hook
+	a.injectCwdHook(state, ch)
+	return nil
+}
+
+// SaveMultiplexer makes a saved session's first shell attach to a tmux or
+// screen session, or stop doing so when tool is empty. With list set the
+// host's sessions are offered after connecting.
+func (a *App) SaveMultiplexer(name, tool, session string, list bool) error {
+	var m *config.Multiplexer
+	switch tool {
+	case "":
+	case mux.Tmux, mux.Screen:
+		if session == "" {
+			return fmt.Errorf("multiplexer session name required")
+		}
+		m = &config.Multiplexer{Tool: tool, Name: session}
+	default:
+		return fmt.Errorf("unknown multiplexer %q", tool)
+	}
+	if err := a.sessionMgr.SetMultiplexer(name, m, list); err != nil {
+		return err
+	}
+
+	a.sessionsLock.Lock()
+	for _, s := range a.sessions {
+		if s.Name == name {
+			s.Config.Multiplexer = m
+			s.Config.ListMultiplexers = list
+		}
+	}
+	a.sessionsLock.Unlock()
+	return nil
+}
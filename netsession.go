@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"Genpilot/internal/config"
+	sshclient "Genpilot/internal/ssh"
+	"Genpilot/internal/telnet"
+)
+
+// dialTimeout bounds connecting to telnet and raw TCP hosts
+const dialTimeout = 10 * time.Second
+
+// errHostClosed is how telnet and raw TCP shells end; they have no exit status
+var errHostClosed = errors.New("connection closed by host")
+
+// rawConn is a plain TCP connection behind a terminal. It has no way to
+// report a window size.
+type rawConn struct {
+	net.Conn
+}
+
+func (rawConn) WindowChange(rows, cols int) error {
+	return nil
+}
+
+// startTelnetShell connects to the session's telnet server
+func startTelnetShell(cfg config.Session, rows, cols int) (*startedShell, error) {
+	port := cfg.Port
+	if port == 0 {
+		port = 23
+	}
+	conn, err := telnet.Dial(net.JoinHostPort(cfg.Host, strconv.Itoa(port)), telnet.Options{Rows: rows, Cols: cols}, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("connection failed: %w", err)
+	}
+	return netShell(conn, conn), nil
+}
+
+// startRawShell opens a TCP socket to the session's host and port
+func startRawShell(cfg config.Session) (*startedShell, error) {
+	if cfg.Port == 0 {
+		return nil, fmt.Errorf("raw TCP session %s has no port", cfg.Name)
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)), dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("connection failed: %w", err)
+	}
+	rc := rawConn{conn}
+	return netShell(rc, rc), nil
+}
+
+// netShell wraps a network connection that ends when the host hangs up
+func netShell(process shellProcess, rw io.ReadWriteCloser) *startedShell {
+	return &startedShell{
+		process: process,
+		stdin:   rw,
+		outputs: []io.Reader{rw},
+		wait: func() (sshclient.ExitStatus, error) {
+			return sshclient.ExitStatus{Code: -1}, errHostClosed
+		},
+	}
+}
+
+// ConnectTelnet opens a telnet session. Saved settings of the session
+// called name apply, such as login scripts and logging.
+func (a *App) ConnectTelnet(id, name, host string, port int) (string, error) {
+	return a.connectNetwork(id, name, config.ProtocolTelnet, host, port)
+}
+
+// ConnectRaw opens a terminal on a plain TCP socket
+func (a *App) ConnectRaw(id, name, host string, port int) (string, error) {
+	return a.connectNetwork(id, name, config.ProtocolRaw, host, port)
+}
+
+func (a *App) connectNetwork(id, name, protocol, host string, port int) (string, error) {
+	var cfg config.Session
+	if a.sessionMgr != nil {
+		cfg, _ = a.sessionMgr.FindSession(name)
+	}
+	cfg.Protocol = protocol
+	cfg.Host = host
+	cfg.Port = port
+
+	if err := a.startShellSession(id, name, host, "", cfg, nil); err != nil {
+		return "", err
+	}
+	return "Connected", nil
+}
+
+// SaveProtocol sets how a saved session connects: ssh, telnet, raw or local
+func (a *App) SaveProtocol(name, protocol string) error {
+	switch protocol {
+	case "", config.ProtocolSSH, config.ProtocolLocal, config.ProtocolTelnet, config.ProtocolRaw:
+	default:
+		return fmt.Errorf("unknown protocol %q", protocol)
+	}
+	return a.sessionMgr.SetProtocol(name, protocol)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"Genpilot/internal/multiexec"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"github.com/zalando/go-keyring"
+)
+
+// Finished runs are kept for their reports only this long, and only this many
+const (
+	finishedRunTTL  = time.Hour
+	maxFinishedRuns = 10
+)
+
+// parallelRun tracks a command running across several saved sessions
+type parallelRun struct {
+	mu      sync.Mutex
+	command string
+	start   time.Time
+	cancel  context.CancelFunc
+	results []multiexec.HostResult // finished hosts, in completion order
+	report  *multiexec.Report      // set once every host has finished
+	done    time.Time              // when the report was set
+}
+
+// RunOutput is a chunk of output streamed from one host of a parallel run
+type RunOutput struct {
+	Name   string `json:"name"`
+	Host   string `json:"host"`
+	Stream string `json:"stream"`
+	Data   string `json:"data"`
+}
+
+// RunParallel runs a command non-interactively on the named saved sessions
+// and every session in group. The caller picks runID so it can subscribe
+// first. Output, per-host results and the final report are streamed as
+// run-output-, run-host-done- and run-done- events.
+func (a *App) RunParallel(runID string, names []string, group string, command string, concurrency int, timeoutSec int) error {
+	if runID == "" {
+		return fmt.Errorf("run ID required")
+	}
+	targets, err := a.runTargets(names, group)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no hosts selected")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &parallelRun{
+		command: command,
+		start:   time.Now(),
+		cancel:  cancel,
+	}
+
+	a.runsLock.Lock()
+	if _, exists := a.runs[runID]; exists {
+		a.runsLock.Unlock()
+		cancel()
+		return fmt.Errorf("run %s already exists", runID)
+	}
+	a.pruneRuns()
+	a.runs[runID] = run
+	a.runsLock.Unlock()
+
+	opts := multiexec.Options{
+		Concurrency: concurrency,
+		Timeout:     time.Duration(timeoutSec) * time.Second,
+	}
+	callbacks := multiexec.Callbacks{
+		Output: func(t multiexec.Target, stream string, data []byte) {
+			if a.ctx != nil {
+				runtime.EventsEmit(a.ctx, "run-output-"+runID, RunOutput{
+					Name:   t.Name,
+					Host:   t.Host,
+					Stream: stream,
+					Data:   string(data),
+				})
+			}
+		},
+		Done: func(result multiexec.HostResult) {
+			run.mu.Lock()
+			run.results = append(run.results, result)
+			run.mu.Unlock()
+			if a.ctx != nil {
+				runtime.EventsEmit(a.ctx, "run-host-done-"+runID, result)
+			}
+		},
+	}
+
+	go func() {
+		defer cancel()
+		results := multiexec.Run(ctx, command, targets, opts, callbacks)
+		report := multiexec.NewReport(command, run.start, results)
+
+		run.mu.Lock()
+		run.report = &report
+		run.done = time.Now()
+		run.mu.Unlock()
+
+		a.runsLock.Lock()
+		a.pruneRuns()
+		a.runsLock.Unlock()
+
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "run-done-"+runID, report)
+		}
+	}()
+
+	return nil
+}
+
+// pruneRuns forgets finished runs past finishedRunTTL and all but the
+// newest maxFinishedRuns, so their output doesn't pile up. The caller must
+// hold runsLock.
+func (a *App) pruneRuns() {
+	type finished struct {
+		id   string
+		done time.Time
+	}
+	var kept []finished
+	for id, run := range a.runs {
+		run.mu.Lock()
+		done := run.done
+		run.mu.Unlock()
+
+		if done.IsZero() {
+			continue
+		}
+		if time.Since(done) > finishedRunTTL {
+			delete(a.runs, id)
+			continue
+		}
+		kept = append(kept, finished{id, done})
+	}
+
+	if len(kept) <= maxFinishedRuns {
+		return
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].done.After(kept[j].done) })
+	for _, f := range kept[maxFinishedRuns:] {
+		delete(a.runs, f.id)
+	}
+}
+
+// runTargets resolves saved sessions by name and group into run targets
+func (a *App) runTargets(names []string, group string) ([]multiexec.Target, error) {
+	if a.sessionMgr == nil {
+		return nil, fmt.Errorf("session manager not available")
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, n := range names {
+		wanted[n] = true
+	}
+
+	var targets []multiexec.Target
+	for _, s := range a.sessionMgr.GetAllSessions() {
+		if !wanted[s.Name] && (group == "" || s.Group != group) {
+			continue
+		}
+		delete(wanted, s.Name)
+
+		pass, _ := keyring.Get("Genpilot", s.Name)
+		port := s.Port
+		if port == 0 {
+			port = 22
+		}
+		targets = append(targets, multiexec.Target{
+			Name:     s.Name,
+			Host:     s.Host,
+			Port:     port,
+			User:     s.Username,
+			Password: pass,
+			KeyPath:  s.PrivateKey,
+		})
+	}
+
+	for n := range wanted {
+		return nil, fmt.Errorf("session %s not found", n)
+	}
+	return targets, nil
+}
+
+// GetRunReport returns the results of a parallel run. While hosts are still
+// running it contains only the hosts that have finished.
+func (a *App) GetRunReport(runID string) (multiexec.Report, error) {
+	a.runsLock.Lock()
+	run, ok := a.runs[runID]
+	a.runsLock.Unlock()
+
+	if !ok {
+		return multiexec.Report{}, fmt.Errorf("run %s not found", runID)
+	}
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if run.report != nil {
+		return *run.report, nil
+	}
+	results := append([]multiexec.HostResult(nil), run.results...)
+	return multiexec.NewReport(run.command, run.start, results), nil
+}
+
+// ExportRunResults writes the report of a parallel run as JSON
+func (a *App) ExportRunResults(runID string, path string) error {
+	report, err := a.GetRunReport(runID)
+	if err != nil {
+		return err
+	}
+	return report.WriteJSON(path)
+}
+
+// CancelRun aborts the hosts of a parallel run that haven't finished
+func (a *App) CancelRun(runID string) {
+	a.runsLock.Lock()
+	run, ok := a.runs[runID]
+	a.runsLock.Unlock()
+
+	if ok {
+		run.cancel()
+	}
+}
+
+// ClearRun cancels a parallel run if needed and forgets its results
+func (a *App) ClearRun(runID string) {
+	a.runsLock.Lock()
+	run, ok := a.runs[runID]
+	delete(a.runs, runID)
+	a.runsLock.Unlock()
+
+	if ok {
+		run.cancel()
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPruneRuns(t *testing.T) {
+	now := time.Now()
+	a := &App{runs: map[string]*parallelRun{
+		"running": {},
+		"stale":   {done: now.Add(-2 * finishedRunTTL)},
+	}}
+	for i := 0; i < maxFinishedRuns+2; i++ {
+		a.runs[fmt.Sprintf("run%d", i)] = &parallelRun{done: now.Add(-time.Duration(i) * time.Minute)}
+	}
+
+	a.pruneRuns()
+
+	if _, ok := a.runs["running"]; !ok {
+		t.Error("unfinished run was pruned")
+	}
+	if _, ok := a.runs["stale"]; ok {
+		t.Error("run past its TTL was kept")
+	}
+	for i := 0; i < maxFinishedRuns+2; i++ {
+		_, ok := a.runs[fmt.Sprintf("run%d", i)]
+		if want := i < maxFinishedRuns; ok != want {
+			t.Errorf("run%d kept = %v, want %v", i, ok, want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+
+	"Genpilot/internal/fileinfo"
+	"Genpilot/internal/sftp"
+)
+
+// ChmodOptions describe a permission change. Modes are octal (755) or
+// symbolic (u+x,g-w); an empty one leaves files or directories alone.
+type ChmodOptions struct {
+	FileMode  string `json:"file_mode"`
+	DirMode   string `json:"dir_mode"`
+	Recursive bool   `json:"recursive"`
+	DryRun    bool   `json:"dry_run"` // only report what would change
+}
+
+// ChownOptions describe an ownership change. Owner and group are names or
+// numeric ids; an empty one is kept.
+type ChownOptions struct {
+	Owner     string `json:"owner"`
+	Group     string `json:"group"`
+	Recursive bool   `json:"recursive"`
+	DryRun    bool   `json:"dry_run"`
+}
+
+// ChangeMode changes permissions of remote files and returns what changed,
+// with an error for each file that couldn't be changed
+func (a *App) ChangeMode(id string, paths []string, opts ChmodOptions) ([]sftp.Change, error) {
+	a.sessionsLock.RLock()
+	s, ok := a.sessions[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok || s.SFTPClient == nil {
+		return nil, fmt.Errorf("not connected for session %s", id)
+	}
+	if opts.FileMode == "" && opts.DirMode == "" {
+		return nil, fmt.Errorf("mode required")
+	}
+
+	fileMode, err := parseModeOption(opts.FileMode)
+	if err != nil {
+		return nil, err
+	}
+	dirMode, err := parseModeOption(opts.DirMode)
+	if err != nil {
+		return nil, err
+	}
+	return s.SFTPClient.ChmodAll(paths, fileMode, dirMode, opts.Recursive, opts.DryRun), nil
+}
+
+// ChangeOwner changes the owner and/or group of remote files, like chown
+// and chgrp, and returns what changed
+func (a *App) ChangeOwner(id string, paths []string, opts ChownOptions) ([]sftp.Change, error) {
+	a.sessionsLock.RLock()
+	s, ok := a.sessions[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok || s.SFTPClient == nil {
+		return nil, fmt.Errorf("not connected for session %s", id)
+	}
+	if opts.Owner == "" && opts.Group == "" {
+		return nil, fmt.Errorf("owner or group required")
+	}
+
+	var uid, gid *uint32
+	if opts.Owner != "" {
+		id, err := s.SFTPClient.LookupID(opts.Owner, false)
+		if err != nil {
+			return nil, err
+		}
+		uid = &id
+	}
+	if opts.Group != "" {
+		id, err := s.SFTPClient.LookupID(opts.Group, true)
+		if err != nil {
+			return nil, err
+		}
+		gid = &id
+	}
+	return s.SFTPClient.ChownAll(paths, uid, gid, opts.Recursive, opts.DryRun), nil
+}
+
+// parseModeOption parses a mode, nil if it is empty
+func parseModeOption(mode string) (*fileinfo.ModeSpec, error) {
+	if mode == "" {
+		return nil, nil
+	}
+	spec, err := fileinfo.ParseMode(mode)
+	if err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
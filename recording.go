@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"Genpilot/internal/logging"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// PlaybackInfo describes an opened recording to the frontend
+type PlaybackInfo struct {
+	ID       string  `json:"id"`
+	Title    string  `json:"title"`
+	Width    int     `json:"width"`
+	Height   int     `json:"height"`
+	Duration float64 `json:"duration"`
+}
+
+// PlaybackSize is emitted when a recording changes terminal size
+type PlaybackSize struct {
+	Cols int `json:"cols"`
+	Rows int `json:"rows"`
+}
+
+// StartRecording records a terminal in asciicast v2 format and returns the file path
+func (a *App) StartRecording(id string, recordInput bool) (string, error) {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	var state *SessionState
+	if ok {
+		state = a.sessions[ch.SessionID]
+	}
+	rows, cols := 0, 0
+	if ok {
+		rows, cols = ch.Rows, ch.Cols
+	}
+	a.sessionsLock.RUnlock()
+
+	if !ok || state == nil {
+		return "", fmt.Errorf("terminal %s not connected", id)
+	}
+
+	dir, err := logging.DefaultRecordingDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := logging.ExpandTemplate("{name}_{date}_{time}.cast", dir, logging.TemplateVars{
+		Name: state.Name,
+		Host: state.Host,
+		User: state.User,
+	}, time.Now())
+
+	title := fmt.Sprintf("%s@%s", state.User, state.Host)
+	if err := ch.Recorder.Start(path, cols, rows, title, recordInput); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// StopRecording finishes the recording of a terminal
+func (a *App) StopRecording(id string) {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	a.sessionsLock.RUnlock()
+
+	if ok {
+		ch.Recorder.Stop()
+	}
+}
+
+// GetRecordingPath returns the file a terminal is being recorded to, or "" if it isn't
+func (a *App) GetRecordingPath(id string) string {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok || !ch.Recorder.IsRecording() {
+		return ""
+	}
+	return ch.Recorder.GetFilePath()
+}
+
+// SelectRecordingFile asks the user for a recording to open
+func (a *App) SelectRecordingFile() (string, error) {
+	return runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Open Recording",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Recordings (*.cast)", Pattern: "*.cast"},
+		},
+	})
+}
+
+// OpenRecording loads a recording for playback. It starts paused; output is
+// streamed as playback-data-<id>, playback-resize-<id>, playback-reset-<id>
+// and playback-state-<id> events.
+func (a *App) OpenRecording(path string) (PlaybackInfo, error) {
+	pid := fmt.Sprintf("play_%d", time.Now().UnixNano())
+
+	player, err := logging.OpenPlayer(path, logging.PlayerCallbacks{
+		Output: func(data string) {
+			runtime.EventsEmit(a.ctx, "playback-data-"+pid, data)
+		},
+		Resize: func(cols, rows int) {
+			runtime.EventsEmit(a.ctx, "playback-resize-"+pid, PlaybackSize{Cols: cols, Rows: rows})
+		},
+		Reset: func() {
+			runtime.EventsEmit(a.ctx, "playback-reset-"+pid)
+		},
+		State: func(state logging.PlayerState) {
+			runtime.EventsEmit(a.ctx, "playback-state-"+pid, state)
+		},
+	})
+	if err != nil {
+		return PlaybackInfo{}, err
+	}
+
+	a.playersLock.Lock()
+	a.players[pid] = player
+	a.playersLock.Unlock()
+
+	return PlaybackInfo{
+		ID:       pid,
+		Title:    player.Header.Title,
+		Width:    player.Header.Width,
+		Height:   player.Header.Height,
+		Duration: player.Duration(),
+	}, nil
+}
+
+func (a *App) player(pid string) (*logging.Player, error) {
+	a.playersLock.Lock()
+	defer a.playersLock.Unlock()
+
+	p, ok := a.players[pid]
+	if !ok {
+		return nil, fmt.Errorf("recording %s not open", pid)
+	}
+	return p, nil
+}
+
+// PlaybackPlay starts or resumes playback
+func (a *App) PlaybackPlay(pid string) error {
+	p, err := a.player(pid)
+	if err != nil {
+		return err
+	}
+	p.Play()
+	return nil
+}
+
+// PlaybackPause pauses playback
+func (a *App) PlaybackPause(pid string) error {
+	p, err := a.player(pid)
+	if err != nil {
+		return err
+	}
+	p.Pause()
+	return nil
+}
+
+// PlaybackSeek jumps to a position in seconds
+func (a *App) PlaybackSeek(pid string, seconds float64) error {
+	p, err := a.player(pid)
+	if err != nil {
+		return err
+	}
+	p.Seek(seconds)
+	return nil
+}
+
+// PlaybackSetSpeed changes the playback rate, e.g. 2 for double speed
+func (a *App) PlaybackSetSpeed(pid string, speed float64) error {
+	if speed <= 0 {
+		return fmt.Errorf("invalid speed %v", speed)
+	}
+	p, err := a.player(pid)
+	if err != nil {
+		return err
+	}
+	p.SetSpeed(speed)
+	return nil
+}
+
+// CloseRecording stops playback and releases the recording
+func (a *App) CloseRecording(pid string) {
+	a.playersLock.Lock()
+	p, ok := a.players[pid]
+	delete(a.players, pid)
+	a.playersLock.Unlock()
+
+	if ok {
+		p.Close()
+	}
+}
+
+// ExportRecording renders a recording through a terminal emulator and saves
+// it as a plain-text transcript ("text") or a colored HTML page ("html")
+func (a *App) ExportRecording(path, format, outPath string) error {
+	return logging.ExportCast(path, outPath, format)
+}
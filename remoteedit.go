@@ -0,0 +1,369 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"Genpilot/internal/cmdline"
+	"Genpilot/internal/config"
+	"Genpilot/internal/editor"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// editPoll is how often edited files are checked for saves
+const editPoll = time.Second
+
+// RemoteEdit describes a remote file open in a local editor, as returned
+// to the frontend and emitted on edits-<id> events
+type RemoteEdit struct {
+	ID         int    `json:"id"`
+	RemotePath string `json:"remote_path"`
+	LocalPath  string `json:"local_path"`
+	Status     string `json:"status"` // editing, uploading, uploaded, conflict or error
+	Message    string `json:"message,omitempty"`
+	Uploads    int    `json:"uploads"`
+}
+
+// remoteEdit is a file being edited. upload serializes uploads; mu guards
+// the fields.
+type remoteEdit struct {
+	upload sync.Mutex
+
+	mu      sync.Mutex
+	watcher *editor.Watcher
+	stopped bool
+	info    RemoteEdit
+	size    int64     // remote size when last downloaded or uploaded
+	modTime time.Time // and its modification time
+}
+
+func (e *remoteEdit) get() RemoteEdit {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.info
+}
+
+func (e *remoteEdit) set(status, message string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.info.Status = status
+	e.info.Message = message
+}
+
+// editSession holds a session's edited files and their workspace
+type editSession struct {
+	workspace *editor.Workspace
+	edits     map[int]*remoteEdit
+	next      int
+}
+
+// GetSettings returns the application settings
+func (a *App) GetSettings() config.Settings {
+	if a.settingsMgr == nil {
+		return config.Settings{}
+	}
+	return a.settingsMgr.Get()
+}
+
+// SaveEditor sets the command used to edit remote files, empty for the
+// system's default application
+func (a *App) SaveEditor(command string) error {
+	if a.settingsMgr == nil {
+		return fmt.Errorf("settings storage unavailable")
+	}
+	if command != "" {
+		if _, err := cmdline.Split(command); err != nil {
+			return err
+		}
+	}
+	return a.settingsMgr.SetEditor(command)
+}
+
+// EditRemoteFile downloads a remote file into a private workspace and
+// opens it in the configured editor. Each save is uploaded back unless the
+// remote file changed meanwhile, which is reported as a conflict.
+func (a *App) EditRemoteFile(id, remotePath string) (RemoteEdit, error) {
+	a.sessionsLock.RLock()
+	s, ok := a.sessions[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok || s.SFTPClient == nil {
+		return RemoteEdit{}, fmt.Errorf("not connected for session %s", id)
+	}
+
+	info, err := s.SFTPClient.Stat(remotePath)
+	if err != nil {
+		return RemoteEdit{}, err
+	}
+	if info.IsDir() {
+		return RemoteEdit{}, fmt.Errorf("%s is a directory", remotePath)
+	}
+
+	a.editsLock.Lock()
+	es, ok := a.edits[id]
+	if !ok {
+		ws, err := editor.NewWorkspace()
+		if err != nil {
+			a.editsLock.Unlock()
+			return RemoteEdit{}, fmt.Errorf("create edit workspace: %w", err)
+		}
+		es = &editSession{workspace: ws, edits: make(map[int]*remoteEdit)}
+		a.edits[id] = es
+	}
+	es.next++
+	editID := es.next
+	a.editsLock.Unlock()
+
+	localPath, err := es.workspace.File(remotePath)
+	if err != nil {
+		return RemoteEdit{}, err
+	}
+	if err := s.SFTPClient.Download(remotePath, localPath); err != nil {
+		os.RemoveAll(filepath.Dir(localPath))
+		return RemoteEdit{}, fmt.Errorf("download %s: %w", remotePath, err)
+	}
+
+	var editorCmd string
+	if a.settingsMgr != nil {
+		editorCmd = a.settingsMgr.Get().Editor
+	}
+	if err := editor.Open(editorCmd, localPath); err != nil {
+		os.RemoveAll(filepath.Dir(localPath))
+		return RemoteEdit{}, err
+	}
+
+	e := &remoteEdit{
+		info: RemoteEdit{
+			ID:         editID,
+			RemotePath: remotePath,
+			LocalPath:  localPath,
+			Status:     "editing",
+		},
+		size:    info.Size(),
+		modTime: info.ModTime(),
+	}
+	e.watcher = editor.Watch(localPath, editPoll, func() {
+		a.uploadEdit(id, e, false)
+	})
+
+	a.editsLock.Lock()
+	if a.edits[id] != es {
+		// The session ended while the file was downloading
+		a.editsLock.Unlock()
+		e.watcher.Stop()
+		os.RemoveAll(filepath.Dir(localPath))
+		return RemoteEdit{}, fmt.Errorf("not connected for session %s", id)
+	}
+	es.edits[editID] = e
+	a.editsLock.Unlock()
+
+	runtime.LogInfo(a.ctx, "Editing "+remotePath+" as "+localPath)
+	a.emitEdits(id)
+	return e.get(), nil
+}
+
+// uploadEdit uploads an edited file. Unless force is set it first checks
+// the remote file still has the size and time it had when downloaded or
+// last uploaded, and reports a conflict otherwise.
+func (a *App) uploadEdit(id string, e *remoteEdit, force bool) {
+	e.upload.Lock()
+	defer e.upload.Unlock()
+	defer a.emitEdits(id)
+
+	a.sessionsLock.RLock()
+	s, ok := a.sessions[id]
+	a.sessionsLock.RUnlock()
+	if !ok || s.SFTPClient == nil {
+		e.set("error", "Session is no longer connected")
+		return
+	}
+
+	e.mu.Lock()
+	info := e.info
+	size, modTime := e.size, e.modTime
+	e.mu.Unlock()
+
+	if !force {
+		remote, err := s.SFTPClient.Stat(info.RemotePath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			e.set("conflict", "The file was deleted on the server")
+			return
+		case err != nil:
+			e.set("error", err.Error())
+			return
+		case remote.Size() != size || !remote.ModTime().Equal(modTime):
+			e.set("conflict", "The file changed on the server since it was opened")
+			return
+		}
+	}
+
+	e.set("uploading", "")
+	a.emitEdits(id)
+	if err := s.SFTPClient.Upload(info.LocalPath, info.RemotePath); err != nil {
+		e.set("error", "Upload failed: "+err.Error())
+		return
+	}
+
+	remote, err := s.SFTPClient.Stat(info.RemotePath)
+	e.mu.Lock()
+	if err == nil {
+		e.size, e.modTime = remote.Size(), remote.ModTime()
+	}
+	e.info.Uploads++
+	e.info.Status = "uploaded"
+	e.info.Message = "Saved at " + time.Now().Format("15:04:05")
+	e.mu.Unlock()
+}
+
+// findEdit looks up an edited file
+func (a *App) findEdit(id string, editID int) (*remoteEdit, error) {
+	a.editsLock.Lock()
+	defer a.editsLock.Unlock()
+	if es, ok := a.edits[id]; ok {
+		if e, ok := es.edits[editID]; ok {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("edit %d not found", editID)
+}
+
+// OverwriteRemoteEdit uploads an edited file after a conflict, replacing
+// the changes made on the server
+func (a *App) OverwriteRemoteEdit(id string, editID int) error {
+	e, err := a.findEdit(id, editID)
+	if err != nil {
+		return err
+	}
+	a.uploadEdit(id, e, true)
+	if info := e.get(); info.Status == "error" {
+		return errors.New(info.Message)
+	}
+	return nil
+}
+
+// ReloadRemoteEdit downloads an edited file again after a conflict,
+// discarding the local changes. The editor may need to reload it.
+func (a *App) ReloadRemoteEdit(id string, editID int) error {
+	e, err := a.findEdit(id, editID)
+	if err != nil {
+		return err
+	}
+
+	a.sessionsLock.RLock()
+	s, ok := a.sessions[id]
+	a.sessionsLock.RUnlock()
+	if !ok || s.SFTPClient == nil {
+		return fmt.Errorf("not connected for session %s", id)
+	}
+
+	// Stop watching so the download itself isn't taken for a save
+	e.mu.Lock()
+	w := e.watcher
+	e.mu.Unlock()
+	w.Stop()
+	e.upload.Lock()
+	defer e.upload.Unlock()
+	defer a.emitEdits(id)
+
+	info := e.get()
+	remote, err := s.SFTPClient.Stat(info.RemotePath)
+	if err == nil {
+		err = s.SFTPClient.Download(info.RemotePath, info.LocalPath)
+	}
+	e.mu.Lock()
+	if err == nil {
+		e.size, e.modTime = remote.Size(), remote.ModTime()
+		e.info.Status, e.info.Message = "editing", "Reloaded from the server"
+	} else {
+		e.info.Status, e.info.Message = "error", "Reload failed: "+err.Error()
+	}
+	if !e.stopped {
+		e.watcher = editor.Watch(info.LocalPath, editPoll, func() {
+			a.uploadEdit(id, e, false)
+		})
+	}
+	e.mu.Unlock()
+	return err
+}
+
+// StopRemoteEdit stops uploading an edited file and deletes the local copy
+func (a *App) StopRemoteEdit(id string, editID int) error {
+	a.editsLock.Lock()
+	var e *remoteEdit
+	if es, ok := a.edits[id]; ok {
+		e = es.edits[editID]
+		delete(es.edits, editID)
+	}
+	a.editsLock.Unlock()
+
+	if e == nil {
+		return fmt.Errorf("edit %d not found", editID)
+	}
+	e.stop()
+	a.emitEdits(id)
+
+	// The connection may only have stayed open for this file
+	a.disconnectIfIdle(id)
+	return nil
+}
+
+// GetRemoteEdits lists a session's files open in an editor
+func (a *App) GetRemoteEdits(id string) []RemoteEdit {
+	a.editsLock.Lock()
+	var edits []*remoteEdit
+	if es, ok := a.edits[id]; ok {
+		for _, e := range es.edits {
+			edits = append(edits, e)
+		}
+	}
+	a.editsLock.Unlock()
+
+	list := make([]RemoteEdit, 0, len(edits))
+	for _, e := range edits {
+		list = append(list, e.get())
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// closeEdits stops a session's edits and removes their workspace. Saves
+// not yet uploaded are lost with it.
+func (a *App) closeEdits(id string) {
+	a.editsLock.Lock()
+	es, ok := a.edits[id]
+	delete(a.edits, id)
+	a.editsLock.Unlock()
+
+	if !ok {
+		return
+	}
+	for _, e := range es.edits {
+		e.stop()
+	}
+	es.workspace.Remove()
+	a.emitEdits(id)
+}
+
+// stop stops watching an edited file and deletes the local copy
+func (e *remoteEdit) stop() {
+	e.mu.Lock()
+	e.stopped = true
+	w := e.watcher
+	e.mu.Unlock()
+	w.Stop()
+	os.RemoveAll(filepath.Dir(e.get().LocalPath))
+}
+
+func (a *App) emitEdits(id string) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, "edits-"+id, a.GetRemoteEdits(id))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"Genpilot/internal/config"
+	"Genpilot/internal/logging"
+)
+
+// startShellLog starts logging a shell channel to a file named from the template
+func (a *App) startShellLog(state *SessionState, ch *ShellChannel, settings config.SessionLog) (string, error) {
+	dir, err := logging.DefaultDir()
+	if err != nil {
+		return "", err
+	}
+
+	path := logging.ExpandTemplate(settings.Template, dir, logging.TemplateVars{
+		Name: state.Name,
+		Host: state.Host,
+		User: state.User,
+	}, time.Now())
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	ch.Logger.SetOptions(logging.Options{
+		StripANSI:  settings.StripANSI,
+		LogInput:   settings.LogInput,
+		MaxSize:    int64(settings.MaxSizeMB) * 1024 * 1024,
+		MaxBackups: settings.MaxBackups,
+	})
+	if err := ch.Logger.Start(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// StartSessionLog starts logging a terminal and returns the log file path
+func (a *App) StartSessionLog(id string, settings config.SessionLog) (string, error) {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	var state *SessionState
+	if ok {
+		state = a.sessions[ch.SessionID]
+	}
+	a.sessionsLock.RUnlock()
+
+	if !ok || state == nil {
+		return "", fmt.Errorf("terminal %s not connected", id)
+	}
+	return a.startShellLog(state, ch, settings)
+}
+
+// StopSessionLog stops logging a terminal
+func (a *App) StopSessionLog(id string) {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	a.sessionsLock.RUnlock()
+
+	if ok {
+		ch.Logger.Stop()
+	}
+}
+
+// GetSessionLogPath returns the active log file of a terminal, or "" if it isn't logged
+func (a *App) GetSessionLogPath(id string) string {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok || !ch.Logger.IsEnabled() {
+		return ""
+	}
+	return ch.Logger.GetFilePath()
+}
+
+// SaveLogSettings sets the terminal logging settings of a saved session
+func (a *App) SaveLogSettings(name string, settings config.SessionLog) error {
+	return a.sessionMgr.SetLogSettings(name, &settings)
+}
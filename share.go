@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"Genpilot/internal/share"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ShareInfo describes a shared terminal, as returned to the frontend and
+// emitted on share-<id> events
+type ShareInfo struct {
+	URL        string         `json:"url"`
+	AllowInput bool           `json:"allow_input"`
+	Viewers    []share.Viewer `json:"viewers"`
+}
+
+// shareTap passes a terminal's output to its share, if it has one
+type shareTap struct {
+	mu sync.Mutex
+	s  *share.Share
+}
+
+func (t *shareTap) get() *share.Share {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.s
+}
+
+// swap installs a share, or none, and returns the one it replaces
+func (t *shareTap) swap(s *share.Share) *share.Share {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	old := t.s
+	t.s = s
+	return old
+}
+
+func (t *shareTap) write(frame string) {
+	if s := t.get(); s != nil {
+		s.Write([]byte(frame))
+	}
+}
+
+func (t *shareTap) resize(rows, cols int) {
+	if s := t.get(); s != nil {
+		s.Resize(rows, cols)
+	}
+}
+
+func (t *shareTap) close() {
+	if s := t.swap(nil); s != nil {
+		s.Close()
+	}
+}
+
+// StartShare serves a terminal to viewers in a browser on addr (host:port,
+// "127.0.0.1:0" if empty). The returned URL carries a random token; the
+// share is read-only unless allowInput is set.
+func (a *App) StartShare(id, addr string, allowInput bool) (ShareInfo, error) {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	var rows, cols int
+	if ok {
+		rows, cols = ch.Rows, ch.Cols
+	}
+	a.sessionsLock.RUnlock()
+
+	if !ok {
+		return ShareInfo{}, fmt.Errorf("terminal %s not connected", id)
+	}
+	if ch.Share.get() != nil {
+		return ShareInfo{}, fmt.Errorf("terminal %s is already shared", id)
+	}
+
+	s, err := share.Start(share.Options{
+		Addr:       addr,
+		AllowInput: allowInput,
+		Rows:       rows,
+		Cols:       cols,
+		History:    ch.Scrollback.Snapshot(),
+		Input: func(data string) {
+			a.WriteToTerminal(id, data)
+		},
+		Changed: func() {
+			a.emitShare(ch)
+		},
+	})
+	if err != nil {
+		return ShareInfo{}, fmt.Errorf("share failed: %w", err)
+	}
+	if old := ch.Share.swap(s); old != nil {
+		// Shared twice at once; keep the newer one
+		old.Close()
+	}
+
+	// Not the URL: its token is all that guards the share
+	runtime.LogInfo(a.ctx, "Sharing terminal "+id+" on "+s.Addr())
+	a.emitShare(ch)
+	return shareInfo(s), nil
+}
+
+// GetShare returns how a terminal is shared, or nil if it isn't
+func (a *App) GetShare(id string) (*ShareInfo, error) {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("terminal %s not connected", id)
+	}
+	s := ch.Share.get()
+	if s == nil {
+		return nil, nil
+	}
+	info := shareInfo(s)
+	return &info, nil
+}
+
+// SetShareInput lets viewers of a shared terminal type, or stops them
+func (a *App) SetShareInput(id string, allow bool) error {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("terminal %s not connected", id)
+	}
+	s := ch.Share.get()
+	if s == nil {
+		return fmt.Errorf("terminal %s is not shared", id)
+	}
+	s.SetAllowInput(allow)
+	a.emitShare(ch)
+	return nil
+}
+
+// KickShareViewer disconnects one viewer of a shared terminal
+func (a *App) KickShareViewer(id string, viewerID int) error {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("terminal %s not connected", id)
+	}
+	s := ch.Share.get()
+	if s == nil || !s.Kick(viewerID) {
+		return fmt.Errorf("viewer %d not found", viewerID)
+	}
+	return nil
+}
+
+// StopShare revokes a terminal's share URL and disconnects its viewers
+func (a *App) StopShare(id string) error {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("terminal %s not connected", id)
+	}
+	ch.Share.close()
+	a.emitShare(ch)
+	return nil
+}
+
+func shareInfo(s *share.Share) ShareInfo {
+	return ShareInfo{URL: s.URL(), AllowInput: s.AllowInput(), Viewers: s.Viewers()}
+}
+
+// emitShare reports a terminal's share state, null once it has stopped
+func (a *App) emitShare(ch *ShellChannel) {
+	if a.ctx == nil {
+		return
+	}
+	var info *ShareInfo
+	if s := ch.Share.get(); s != nil {
+		i := shareInfo(s)
+		info = &i
+	}
+	runtime.EventsEmit(a.ctx, "share-"+ch.ID, info)
+}
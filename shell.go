@@ -0,0 +1,645 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"Genpilot/internal/automation"
+	"Genpilot/internal/config"
+	"Genpilot/internal/history"
+	"Genpilot/internal/logging"
+	"Genpilot/internal/mux"
+	"Genpilot/internal/sftp"
+	sshclient "Genpilot/internal/ssh"
+	"Genpilot/internal/terminal"
+	"Genpilot/internal/transfer"
+	"Genpilot/internal/zmodem"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/crypto/ssh"
+)
+
+// ShellChannel is one interactive shell running over a session's connection,
+// or on this machine for local sessions. The first shell of a session uses
+// the session ID as its channel ID.
+type ShellChannel struct {
+	ID         string
+	SessionID  string
+	Process    shellProcess
+	Stdin      io.WriteCloser
+	Rows       int
+	Cols       int
+	Logger     *logging.Logger
+	Recorder   *logging.Recorder
+	Scrollback *terminal.Scrollback
+	Output     *terminal.Batcher
+	Triggers   *automation.Triggers
+	History    *history.Tracker
+	Expect     *expectTaps
+	Share      *shareTap
+	Charset    *terminal.Charset // nil when the host uses UTF-8
+
+	cwdMu sync.Mutex
+	cwd   string // last directory the shell reported
+
+	zmodem *zmodem.Session // transfer holding the terminal, if any
+}
+
+// workingDir returns the last directory the shell reported, "" if none
+func (ch *ShellChannel) workingDir() string {
+	ch.cwdMu.Lock()
+	defer ch.cwdMu.Unlock()
+	return ch.cwd
+}
+
+// setWorkingDir records a directory the shell reported and whether it changed
+func (ch *ShellChannel) setWorkingDir(dir string) bool {
+	ch.cwdMu.Lock()
+	defer ch.cwdMu.Unlock()
+	changed := ch.cwd != dir
+	ch.cwd = dir
+	return changed
+}
+
+// shellProcess is what runs behind a terminal: an SSH session or a local shell
+type shellProcess interface {
+	WindowChange(rows, cols int) error
+	Close() error
+}
+
+// startedShell is a shell that is running but not attached to a terminal yet
+type startedShell struct {
+	process shellProcess
+	stdin   io.WriteCloser
+	outputs []io.Reader
+	// wait returns how the shell ended; an error means it didn't say
+	wait func() (sshclient.ExitStatus, error)
+}
+
+// ShellInfo describes a shell channel to the frontend
+type ShellInfo struct {
+	ID   string `json:"id"`
+	Rows int    `json:"rows"`
+	Cols int    `json:"cols"`
+}
+
+// expectTaps passes shell output to the expecters of running scripts
+type expectTaps struct {
+	mu   sync.Mutex
+	list []*automation.Expecter
+	done bool
+}
+
+func (t *expectTaps) add(e *automation.Expecter) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done {
+		e.Close()
+		return
+	}
+	t.list = append(t.list, e)
+}
+
+func (t *expectTaps) remove(e *automation.Expecter) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, x := range t.list {
+		if x == e {
+			t.list = append(t.list[:i], t.list[i+1:]...)
+			return
+		}
+	}
+}
+
+func (t *expectTaps) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, e := range t.list {
+		e.Write(p)
+	}
+	return len(p), nil
+}
+
+// closeAll fails pending waits once the shell's output has ended
+func (t *expectTaps) closeAll() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, e := range t.list {
+		e.Close()
+	}
+	t.list = nil
+	t.done = true
+}
+
+// Reasons a terminal ended, as reported in shell-closed-<channel id> events.
+// disconnected-<session id> carries the same when the whole session ends;
+// the first terminal shares the session's ID, so the two need their own names.
+const (
+	EndExit           = "exit"            // the shell exited with a status code
+	EndSignal         = "signal"          // the shell was killed by a signal
+	EndClosed         = "closed"          // the server closed the channel without a status
+	EndConnectionLost = "connection-lost" // the SSH connection dropped
+	EndUser           = "user"            // closed from the app
+)
+
+// DisconnectInfo says why a terminal ended
+type DisconnectInfo struct {
+	Reason  string `json:"reason"`
+	Code    int    `json:"code"` // shell exit code, -1 if none was reported
+	Signal  string `json:"signal,omitempty"`
+	Message string `json:"message"`
+	Session bool   `json:"session"` // the whole connection ended, not just this terminal
+}
+
+// exitInfo says how a shell ended from the status its channel reported, or
+// the error it ended with when there was none. lost is what showed the
+// connection itself to be gone, nil if it is still up or there is none;
+// the whole session has to end then, which the result's Session reports.
+func exitInfo(status sshclient.ExitStatus, err, lost error) DisconnectInfo {
+	var missing *ssh.ExitMissingError
+	switch {
+	case lost != nil:
+		return connectionLost(lost)
+	case errors.As(err, &missing):
+		return DisconnectInfo{Reason: EndClosed, Code: -1, Message: "Shell closed without an exit status"}
+	case err != nil:
+		return DisconnectInfo{Reason: EndClosed, Code: -1, Message: "Shell closed: " + err.Error()}
+	case status.Signal != "":
+		return DisconnectInfo{Reason: EndSignal, Code: status.Code, Signal: status.Signal, Message: "Shell killed by signal " + status.Signal}
+	}
+	return DisconnectInfo{Reason: EndExit, Code: status.Code, Message: fmt.Sprintf("Shell exited with code %d", status.Code)}
+}
+
+// connectionLost describes a session whose connection failed with err
+func connectionLost(err error) DisconnectInfo {
+	msg := "Connection lost"
+	if err != nil && err != io.EOF {
+		msg += ": " + err.Error()
+	}
+	return DisconnectInfo{Reason: EndConnectionLost, Code: -1, Message: msg, Session: true}
+}
+
+func (ch *ShellChannel) close() {
+	if ch.Stdin != nil {
+		ch.Stdin.Close()
+	}
+	if ch.Process != nil {
+		ch.Process.Close()
+	}
+	if ch.Output != nil {
+		ch.Output.Close()
+	}
+	ch.Share.close()
+}
+
+// openShell starts a shell for the session and registers it
+func (a *App) openShell(state *SessionState, channelID string, expecter *automation.Expecter) (*ShellChannel, error) {
+	const cols, rows = 80, 24
+
+	charset, err := terminal.NewCharset(state.Config.Charset)
+	if err != nil {
+		return nil, err
+	}
+
+	var started *startedShell
+	switch state.protocol() {
+	case config.ProtocolLocal:
+		started, err = startLocalShell(state.Config, rows, cols)
+	case config.ProtocolTelnet:
+		started, err = startTelnetShell(state.Config, rows, cols)
+	case config.ProtocolRaw:
+		started, err = startRawShell(state.Config)
+	default:
+		// The first shell goes straight into the configured multiplexer
+		command := ""
+		if m := state.Config.Multiplexer; m != nil && channelID == state.ID {
+			command = mux.AttachCommand(m.Tool, m.Name)
+		}
+		started, err = startSSHShell(state.SSHClient, command, rows, cols)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	shared := &shareTap{}
+	ch := &ShellChannel{
+		ID:         channelID,
+		SessionID:  state.ID,
+		Process:    started.process,
+		Stdin:      started.stdin,
+		Rows:       rows,
+		Cols:       cols,
+		Logger:     logging.NewLogger(),
+		Recorder:   logging.NewRecorder(),
+		Scrollback: terminal.NewScrollback(terminal.DefaultScrollbackSize),
+		Expect:     &expectTaps{},
+		Share:      shared,
+		Charset:    charset,
+		Output: terminal.NewBatcher(terminal.DefaultBatcherOptions, func(frame string) {
+			if a.ctx != nil {
+				runtime.EventsEmit(a.ctx, "terminal-data-"+channelID, frame)
+			}
+			shared.write(frame)
+		}),
+	}
+
+	if expecter != nil {
+		ch.Expect.add(expecter)
+	}
+	ch.Triggers = automation.NewTriggers(func(f automation.Firing) {
+		a.fireTrigger(ch, f)
+	})
+	ch.History = history.NewTracker(func(e history.Entry) {
+		a.recordCommand(state, ch, e)
+	})
+
+	a.sessionsLock.Lock()
+	if a.sessions[state.ID] != state {
+		// Disconnected while the shell was starting
+		a.sessionsLock.Unlock()
+		ch.close()
+		return nil, fmt.Errorf("session %s not connected", state.ID)
+	}
+	state.Shells[channelID] = ch
+	a.shells[channelID] = ch
+	rules := a.triggerRules(state)
+	a.sessionsLock.Unlock()
+
+	if err := ch.Triggers.SetRules(rules); err != nil {
+		runtime.LogWarning(a.ctx, "Triggers for "+channelID+": "+err.Error())
+	}
+
+	// Writer that emits events to frontend with the channel ID
+	writer := &eventWriter{
+		expect:     ch.Expect,
+		logger:     ch.Logger,
+		recorder:   ch.Recorder,
+		scrollback: ch.Scrollback,
+		triggers:   ch.Triggers,
+		history:    ch.History,
+		output:     ch.Output,
+		charset:    ch.Charset,
+		onOSC: func(o terminal.OSC) {
+			a.handleOSC(ch, o)
+		},
+	}
+	writer.startZmodem = func(dir zmodem.Direction) *zmodem.Session {
+		return a.startZmodem(state, ch, writer, dir)
+	}
+
+	// Start Copyroutines
+	var copies sync.WaitGroup
+	for _, r := range started.outputs {
+		copies.Add(1)
+		go func() {
+			defer copies.Done()
+			_, _ = io.Copy(writer, r)
+		}()
+	}
+
+	// Monitor shell closure once all of its output has been read
+	go func() {
+		copies.Wait()
+		ch.Expect.closeAll()
+		status, err := started.wait()
+		a.shellExited(state, ch, status, err)
+	}()
+
+	return ch, nil
+}
+
+// startSSHShell starts a shell on an SSH connection, or runs command in
+// the terminal instead if it is set
+func startSSHShell(client *sshclient.Client, command string, rows, cols int) (*startedShell, error) {
+	// Prepare Shell
+	session, err := client.PrepareShell(cols, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup Pipes
+	stdoutPipe, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	stderrPipe, err := session.StderrPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	stdinPipe, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	// Start Shell
+	if command != "" {
+		err = session.Start(command)
+	} else {
+		err = session.Shell()
+	}
+	if err != nil {
+		session.Close()
+		return nil, fmt.Errorf("shell failed: %w", err)
+	}
+
+	return &startedShell{
+		process: session,
+		stdin:   stdinPipe,
+		outputs: []io.Reader{stdoutPipe, stderrPipe},
+		wait: func() (sshclient.ExitStatus, error) {
+			return sshclient.SessionExitStatus(session.Wait())
+		},
+	}, nil
+}
+
+// OpenShell opens another terminal on an existing connection (duplicate tab)
+// and returns the new channel ID for terminal events and input.
+func (a *App) OpenShell(id string) (string, error) {
+	a.sessionsLock.Lock()
+	s, ok := a.sessions[id]
+	if !ok || (s.SSHClient == nil && s.protocol() == config.ProtocolSSH) {
+		a.sessionsLock.Unlock()
+		return "", fmt.Errorf("session %s not connected", id)
+	}
+	if s.nextShell < 2 {
+		s.nextShell = 2
+	}
+	channelID := fmt.Sprintf("%s-%d", id, s.nextShell)
+	s.nextShell++
+	a.sessionsLock.Unlock()
+
+	ch, err := a.openShell(s, channelID, nil)
+	if err != nil {
+		return "", err
+	}
+	a.injectCwdHook(s, ch)
+	return channelID, nil
+}
+
+// shellExited reports how a shell ended. Only the shell is closed, so
+// tunnels and SFTP on the same connection keep working.
+func (a *App) shellExited(state *SessionState, ch *ShellChannel, status sshclient.ExitStatus, err error) {
+	a.sessionsLock.RLock()
+	current := a.shells[ch.ID] == ch
+	a.sessionsLock.RUnlock()
+	if !current {
+		// Closed from the app or along with its session
+		return
+	}
+
+	var lost error
+	if err != nil && state.SSHClient != nil {
+		// No exit status: either the server dropped the channel or the
+		// whole connection is gone
+		lost = state.SSHClient.Ping(5 * time.Second)
+	}
+
+	info := exitInfo(status, err, lost)
+	if info.Session {
+		a.endSession(state.ID, info)
+		return
+	}
+	a.endShell(ch.ID, info, false)
+}
+
+// CloseShell closes a single shell channel. The connection is closed once
+// its last shell and tunnel are gone, unless files are still being
+// transferred or edited over it.
+func (a *App) CloseShell(channelID string) {
+	a.endShell(channelID, DisconnectInfo{Reason: EndUser, Code: -1, Message: "Disconnected"}, true)
+}
+
+// endShell removes a shell channel and reports why it ended. With
+// closeIdle set the connection goes too if nothing else uses it.
+func (a *App) endShell(channelID string, info DisconnectInfo, closeIdle bool) {
+	a.sessionsLock.Lock()
+	ch, ok := a.shells[channelID]
+	if !ok {
+		a.sessionsLock.Unlock()
+		return
+	}
+	delete(a.shells, channelID)
+
+	if s, ok := a.sessions[ch.SessionID]; ok {
+		delete(s.Shells, channelID)
+	}
+	a.sessionsLock.Unlock()
+
+	ch.close()
+	ch.Logger.Stop()
+	ch.Recorder.Stop()
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "shell-closed-"+channelID, info)
+	}
+	a.RemoveBroadcastMember(channelID)
+
+	if closeIdle {
+		a.disconnectIfIdle(ch.SessionID)
+	}
+}
+
+// ListShells returns the open shell channels of a session
+func (a *App) ListShells(id string) []ShellInfo {
+	a.sessionsLock.RLock()
+	defer a.sessionsLock.RUnlock()
+
+	var result []ShellInfo
+	s, ok := a.sessions[id]
+	if !ok {
+		return result
+	}
+
+	for _, ch := range s.Shells {
+		result = append(result, ShellInfo{
+			ID:   ch.ID,
+			Rows: ch.Rows,
+			Cols: ch.Cols,
+		})
+	}
+	return result
+}
+
+func (a *App) ResizeTerminal(id string, rows, cols int) {
+	a.sessionsLock.Lock()
+	ch, ok := a.shells[id]
+	if ok {
+		ch.Rows, ch.Cols = rows, cols
+	}
+	a.sessionsLock.Unlock()
+
+	if ok && ch.Process != nil {
+		ch.Process.WindowChange(rows, cols)
+		ch.Recorder.Resize(cols, rows)
+		ch.Share.resize(rows, cols)
+	}
+}
+
+func (a *App) WriteToTerminal(id string, data string) {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	var transfer *zmodem.Session
+	if ok {
+		transfer = ch.zmodem
+	}
+	a.sessionsLock.RUnlock()
+
+	if transfer != nil {
+		// Keystrokes would corrupt the transfer; Ctrl-C or Ctrl-X cancels it
+		if strings.ContainsAny(data, "\x03\x18") {
+			transfer.Cancel()
+		}
+		return
+	}
+	if ok && ch.Stdin != nil {
+		ch.Logger.WriteInput([]byte(data))
+		ch.Recorder.WriteInput([]byte(data))
+		ch.History.Input([]byte(data))
+		ch.Stdin.Write(ch.Charset.Encode([]byte(data)))
+	}
+}
+
+// AckTerminalData tells the backend the view has rendered one frame of
+// terminal-data. Output is held back while too many frames are unrendered.
+func (a *App) AckTerminalData(id string) {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	a.sessionsLock.RUnlock()
+
+	if ok {
+		ch.Output.Ack()
+	}
+}
+
+// GetScrollback returns a terminal's recent output so the view can be rebuilt
+func (a *App) GetScrollback(id string) (string, error) {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok {
+		return "", fmt.Errorf("terminal %s not connected", id)
+	}
+	return string(ch.Scrollback.Snapshot()), nil
+}
+
+// SearchScrollback finds text in a terminal's recent output. Line numbers
+// count from the first line returned by GetScrollback.
+func (a *App) SearchScrollback(id, query string, regex, caseSensitive bool) ([]terminal.Match, error) {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("terminal %s not connected", id)
+	}
+	return ch.Scrollback.Search(query, regex, caseSensitive, 1000)
+}
+
+// protocol returns how the session's shells are reached
+func (s *SessionState) protocol() string {
+	if s.Config.Protocol == "" {
+		return config.ProtocolSSH
+	}
+	return s.Config.Protocol
+}
+
+// posixShell reports whether the session's shells are expected to run a
+// POSIX shell that can be sent shell commands
+func (s *SessionState) posixShell() bool {
+	switch s.protocol() {
+	case config.ProtocolSSH, config.ProtocolLocal:
+		return true
+	}
+	return false
+}
+
+// startShellSession registers a session and opens its first shell. client
+// is the session's SSH connection, or nil for a local shell or telnet.
+func (a *App) startShellSession(id, name, host, user string, cfg config.Session, client *sshclient.Client) error {
+	state := &SessionState{
+		ID:            id,
+		Name:          name,
+		Host:          host,
+		User:          user,
+		Config:        cfg,
+		SSHClient:     client,
+		TransferQueue: transfer.NewTransferQueue(nil, 2),
+		Tunnels:       make(map[string]*sshclient.Tunnel),
+		Shells:        make(map[string]*ShellChannel),
+	}
+	state.ctx, state.cancel = context.WithCancel(context.Background())
+	a.sessionsLock.Lock()
+	a.sessions[id] = state
+	a.sessionsLock.Unlock()
+
+	if client != nil {
+		// Notice when the connection drops underneath us
+		go func() {
+			err := client.Wait()
+			a.connectionClosed(state, err)
+		}()
+	}
+
+	// SFTP and ZMODEM transfers report through the queue
+	state.TransferQueue.SetOnChange(func() {
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "transfer-update-"+id, state.TransferQueue.GetItems())
+		}
+		// A closed terminal may have left the connection open for these
+		if pending, active, _, _ := state.TransferQueue.GetStats(); pending+active == 0 {
+			a.disconnectIfIdle(id)
+		}
+	})
+
+	// Login scripts wait on output that arrives before they start
+	var expecter *automation.Expecter
+	if len(cfg.LoginScript) > 0 {
+		expecter = automation.NewExpecter()
+	}
+
+	// The first shell shares the session ID so existing events keep working
+	shell, err := a.openShell(state, id, expecter)
+	if err != nil {
+		a.DisconnectSession(id)
+		return err
+	}
+
+	if cfg.Log != nil && cfg.Log.AutoStart {
+		if _, err := a.startShellLog(state, shell, *cfg.Log); err != nil {
+			runtime.LogError(a.ctx, "Session log failed for "+id+": "+err.Error())
+		}
+	}
+
+	if client != nil {
+		if sftpClient, err := sftp.NewClient(client.GetClient()); err == nil {
+			state.SFTPClient = sftpClient
+			state.TransferQueue.SetClient(sftpClient.GetSFTPClient())
+		}
+	}
+
+	// The login script waits on the shell for seconds at a time, so it runs
+	// in the background; the cwd hook follows it so they don't interleave
+	if expecter != nil {
+		go func() {
+			a.runLoginScript(state.ctx, id, cfg.LoginScript, expecter, shell)
+			if state.ctx.Err() == nil {
+				a.injectCwdHook(state, shell)
+			}
+		}()
+	} else {
+		a.injectCwdHook(state, shell)
+	}
+
+	if client != nil && cfg.ListMultiplexers {
+		go a.announceMultiplexers(state)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	sshclient "Genpilot/internal/ssh"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestExitInfo(t *testing.T) {
+	tests := []struct {
+		name   string
+		status sshclient.ExitStatus
+		err    error
+		lost   error
+		want   DisconnectInfo
+	}{
+		{
+			name:   "exit code",
+			status: sshclient.ExitStatus{Code: 2},
+			want:   DisconnectInfo{Reason: EndExit, Code: 2, Message: "Shell exited with code 2"},
+		},
+		{
+			name:   "signal",
+			status: sshclient.ExitStatus{Code: 143, Signal: "TERM"},
+			want:   DisconnectInfo{Reason: EndSignal, Code: 143, Signal: "TERM", Message: "Shell killed by signal TERM"},
+		},
+		{
+			name: "no exit status",
+			err:  &ssh.ExitMissingError{},
+			want: DisconnectInfo{Reason: EndClosed, Code: -1, Message: "Shell closed without an exit status"},
+		},
+		{
+			name: "local shell error",
+			err:  errors.New("read /dev/ptmx: input/output error"),
+			want: DisconnectInfo{Reason: EndClosed, Code: -1, Message: "Shell closed: read /dev/ptmx: input/output error"},
+		},
+		{
+			name: "connection gone",
+			err:  &ssh.ExitMissingError{},
+			lost: errors.New("ping timed out"),
+			want: DisconnectInfo{Reason: EndConnectionLost, Code: -1, Message: "Connection lost: ping timed out", Session: true},
+		},
+	}
+	for _, tt := range tests {
+		if got := exitInfo(tt.status, tt.err, tt.lost); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConnectionLost(t *testing.T) {
+	for _, err := range []error{nil, io.EOF} {
+		if got := connectionLost(err); got.Message != "Connection lost" || got.Reason != EndConnectionLost || !got.Session {
+			t.Errorf("connectionLost(%v) = %+v", err, got)
+		}
+	}
+	if got := connectionLost(errors.New("reset by peer")); got.Message != "Connection lost: reset by peer" {
+		t.Errorf("Message %q", got.Message)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"Genpilot/internal/automation"
+	"Genpilot/internal/config"
+)
+
+// GetSnippets returns all saved snippets
+func (a *App) GetSnippets() []config.Snippet {
+	if a.snippetMgr == nil {
+		return []config.Snippet{}
+	}
+	return a.snippetMgr.GetAll()
+}
+
+// GetSessionSnippets returns the snippets offered for a connected session's group
+func (a *App) GetSessionSnippets(id string) []config.Snippet {
+	a.sessionsLock.RLock()
+	var group string
+	if s, ok := a.sessions[id]; ok {
+		group = s.Config.Group
+	}
+	a.sessionsLock.RUnlock()
+
+	if a.snippetMgr == nil {
+		return []config.Snippet{}
+	}
+	return a.snippetMgr.ForGroup(group)
+}
+
+// SaveSnippet adds or updates a snippet and returns it with its ID
+func (a *App) SaveSnippet(s config.Snippet) (config.Snippet, error) {
+	if a.snippetMgr == nil {
+		return s, fmt.Errorf("snippet storage unavailable")
+	}
+	return a.snippetMgr.Set(s)
+}
+
+// DeleteSnippet removes a snippet
+func (a *App) DeleteSnippet(id string) error {
+	if a.snippetMgr == nil {
+		return fmt.Errorf("snippet storage unavailable")
+	}
+	return a.snippetMgr.Delete(id)
+}
+
+// GetSnippetVariables returns the placeholders to ask for before running a snippet
+func (a *App) GetSnippetVariables(id string) ([]string, error) {
+	s, err := a.findSnippet(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Variables(), nil
+}
+
+func (a *App) findSnippet(id string) (config.Snippet, error) {
+	if a.snippetMgr == nil {
+		return config.Snippet{}, fmt.Errorf("snippet storage unavailable")
+	}
+	s, ok := a.snippetMgr.Get(id)
+	if !ok {
+		return s, fmt.Errorf("snippet %s not found", id)
+	}
+	return s, nil
+}
+
+// RunSnippet runs a snippet in a terminal and returns once it is done
+func (a *App) RunSnippet(snippetID, terminalID string, vars map[string]string) error {
+	return a.runSnippet(snippetID, []string{terminalID}, vars)
+}
+
+// RunSnippetBroadcast runs a snippet in every enabled broadcast member at once
+func (a *App) RunSnippetBroadcast(snippetID string, vars map[string]string) error {
+	targets := a.broadcastTargets()
+	if len(targets) == 0 {
+		return fmt.Errorf("no broadcast members enabled")
+	}
+	return a.runSnippet(snippetID, targets, vars)
+}
+
+func (a *App) runSnippet(snippetID string, terminalIDs []string, vars map[string]string) error {
+	s, err := a.findSnippet(snippetID)
+	if err != nil {
+		return err
+	}
+	if s, err = s.Expand(vars); err != nil {
+		return err
+	}
+
+	errs := make([]error, len(terminalIDs))
+	var wg sync.WaitGroup
+	for i, id := range terminalIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := a.runSnippetIn(s, id); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", id, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// runSnippetIn types a snippet's body into a terminal, or plays its macro steps
+func (a *App) runSnippetIn(s config.Snippet, id string) error {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	a.sessionsLock.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("terminal %s not connected", id)
+	}
+
+	if len(s.Steps) == 0 {
+		// One command per line, each followed by Enter
+		body := strings.TrimRight(strings.ReplaceAll(s.Body, "\r\n", "\n"), "\n")
+		a.WriteToTerminal(id, strings.ReplaceAll(body, "\n", "\r")+"\r")
+		return nil
+	}
+
+	exp := automation.NewExpecter()
+	ch.Expect.add(exp)
+	defer ch.Expect.remove(exp)
+	defer exp.Close()
+
+	// Like login scripts, macro input skips logs so secrets stay out of them
+	return automation.Run(context.Background(), s.Steps, exp, ch.Charset.Writer(ch.Stdin))
+}
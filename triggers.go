@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+
+	"Genpilot/internal/automation"
+	"Genpilot/internal/config"
+	"Genpilot/internal/terminal"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// TriggerEvent is the payload of trigger-<id> events
+type TriggerEvent struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Action   string `json:"action"`
+	Message  string `json:"message"`
+	Match    string `json:"match"`
+	Line     string `json:"line"`
+	StartCol int    `json:"start_col"` // cell columns of the match within Line
+	EndCol   int    `json:"end_col"`
+	Color    string `json:"color,omitempty"`
+}
+
+// triggerRules returns the global triggers followed by the session's own
+func (a *App) triggerRules(state *SessionState) []config.Trigger {
+	var rules []config.Trigger
+	if a.triggerMgr != nil {
+		rules = append(rules, a.triggerMgr.GetAll()...)
+	}
+	return append(rules, state.Config.Triggers...)
+}
+
+// reloadTriggers applies changed rules to every open terminal
+func (a *App) reloadTriggers() {
+	a.sessionsLock.RLock()
+	defer a.sessionsLock.RUnlock()
+
+	for _, s := range a.sessions {
+		rules := a.triggerRules(s)
+		for _, ch := range s.Shells {
+			if err := ch.Triggers.SetRules(rules); err != nil {
+				runtime.LogWarning(a.ctx, "Triggers for "+ch.ID+": "+err.Error())
+			}
+		}
+	}
+}
+
+// fireTrigger carries out a trigger's action and reports it to the frontend
+func (a *App) fireTrigger(ch *ShellChannel, f automation.Firing) {
+	t := f.Trigger
+	event := TriggerEvent{
+		ID:       t.ID,
+		Name:     t.Name,
+		Action:   t.Action,
+		Message:  t.Text,
+		Match:    f.Match,
+		Line:     f.Line,
+		StartCol: terminal.StringWidth(f.Line[:f.Start]),
+		EndCol:   terminal.StringWidth(f.Line[:f.End]),
+		Color:    t.Color,
+	}
+
+	switch t.Action {
+	case config.TriggerNotify:
+		if event.Message == "" {
+			event.Message = f.Match
+		}
+
+	case config.TriggerRespond:
+		event.Message = "Sent response"
+		// Not from the output path, the write may wait on the remote window
+		go a.WriteToTerminal(ch.ID, t.Text)
+
+	case config.TriggerSecret:
+		event.Message = "Sent secret " + t.Secret
+		go func() {
+			// Only the session's own rules may send secrets, never a global
+			// one that any host can set off
+			if !a.isSessionTrigger(ch.SessionID, t.ID) {
+				runtime.LogWarning(a.ctx, fmt.Sprintf("Trigger %s: secrets are only sent by session triggers", t.Name))
+				return
+			}
+			secret, err := config.GetSecret(t.Secret)
+			if err != nil {
+				runtime.LogError(a.ctx, fmt.Sprintf("Trigger %s: secret %q: %v", t.Name, t.Secret, err))
+				return
+			}
+			// Straight to stdin so it isn't logged or recorded as input. A
+			// remote that echoes what it reads will still show it.
+			ch.Stdin.Write(ch.Charset.Encode([]byte(secret + "\r")))
+		}()
+	}
+
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "trigger-"+ch.ID, event)
+	}
+}
+
+// isSessionTrigger reports whether a trigger is one of a session's own
+func (a *App) isSessionTrigger(sessionID, triggerID string) bool {
+	a.sessionsLock.RLock()
+	defer a.sessionsLock.RUnlock()
+
+	s, ok := a.sessions[sessionID]
+	if !ok {
+		return false
+	}
+	for _, t := range s.Config.Triggers {
+		if t.ID == triggerID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTriggers returns the global triggers
+func (a *App) GetTriggers() []config.Trigger {
+	if a.triggerMgr == nil {
+		return []config.Trigger{}
+	}
+	return a.triggerMgr.GetAll()
+}
+
+// SaveTrigger adds or updates a global trigger and returns it with its ID
+func (a *App) SaveTrigger(t config.Trigger) (config.Trigger, error) {
+	if a.triggerMgr == nil {
+		return t, fmt.Errorf("trigger storage unavailable")
+	}
+	t, err := a.triggerMgr.Set(t)
+	if err != nil {
+		return t, err
+	}
+	a.reloadTriggers()
+	return t, nil
+}
+
+// SetTriggerEnabled turns a global trigger on or off
+func (a *App) SetTriggerEnabled(id string, enabled bool) error {
+	if a.triggerMgr == nil {
+		return fmt.Errorf("trigger storage unavailable")
+	}
+	if err := a.triggerMgr.SetEnabled(id, enabled); err != nil {
+		return err
+	}
+	a.reloadTriggers()
+	return nil
+}
+
+// DeleteTrigger removes a global trigger
+func (a *App) DeleteTrigger(id string) error {
+	if a.triggerMgr == nil {
+		return fmt.Errorf("trigger storage unavailable")
+	}
+	if err := a.triggerMgr.Delete(id); err != nil {
+		return err
+	}
+	a.reloadTriggers()
+	return nil
+}
+
+// SaveSessionTriggers replaces the triggers of a saved session, including
+// any of its connections that are open
+func (a *App) SaveSessionTriggers(name string, triggers []config.Trigger) error {
+	for i := range triggers {
+		if triggers[i].ID == "" {
+			triggers[i].ID = fmt.Sprintf("%s-%d", config.NewTriggerID(), i)
+		}
+	}
+	if err := a.sessionMgr.SetTriggers(name, triggers); err != nil {
+		return err
+	}
+
+	a.sessionsLock.Lock()
+	for _, s := range a.sessions {
+		if s.Name == name {
+			s.Config.Triggers = triggers
+		}
+	}
+	a.sessionsLock.Unlock()
+
+	a.reloadTriggers()
+	return nil
+}
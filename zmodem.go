@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"Genpilot/internal/transfer"
+	"Genpilot/internal/zmodem"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// zmodemProgressInterval limits how often transfer progress is reported
+const zmodemProgressInterval = 200 * time.Millisecond
+
+// startZmodem takes over a terminal for a transfer the remote side started.
+// It is called by the terminal's writer, which feeds the session until it ends.
+func (a *App) startZmodem(state *SessionState, ch *ShellChannel, w *eventWriter, dir zmodem.Direction) *zmodem.Session {
+	t := &zmodemTransfer{queue: state.TransferQueue, dir: dir}
+	sess := zmodem.NewSession(ch.Stdin, zmodem.Callbacks{
+		Create:   a.createZmodemFile,
+		Choose:   a.chooseZmodemFiles,
+		Start:    t.start,
+		Progress: t.progress,
+		Done:     t.done,
+	})
+	t.sess = sess
+
+	a.sessionsLock.Lock()
+	ch.zmodem = sess
+	a.sessionsLock.Unlock()
+
+	if dir == zmodem.Receive {
+		ch.Output.Write([]byte("\r\n[ZMODEM: receiving files, press Ctrl-C to cancel]\r\n"))
+	} else {
+		ch.Output.Write([]byte("\r\n[ZMODEM: sending files, press Ctrl-C to cancel]\r\n"))
+	}
+
+	go func() {
+		err := sess.Run(dir)
+
+		a.sessionsLock.Lock()
+		ch.zmodem = nil
+		a.sessionsLock.Unlock()
+
+		switch {
+		case errors.Is(err, zmodem.ErrCancelled):
+			ch.Output.Write([]byte("\r\n[ZMODEM: cancelled]\r\n"))
+		case err != nil:
+			ch.Output.Write([]byte("\r\n[ZMODEM: " + err.Error() + "]\r\n"))
+		default:
+			ch.Output.Write([]byte(fmt.Sprintf("\r\n[ZMODEM: %d file(s) transferred]\r\n", t.count())))
+		}
+		w.handBack(sess)
+	}()
+	return sess
+}
+
+// CancelZmodem stops a ZMODEM transfer running in a terminal
+func (a *App) CancelZmodem(id string) error {
+	a.sessionsLock.RLock()
+	ch, ok := a.shells[id]
+	var sess *zmodem.Session
+	if ok {
+		sess = ch.zmodem
+	}
+	a.sessionsLock.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("terminal %s not connected", id)
+	}
+	if sess != nil {
+		sess.Cancel()
+	}
+	return nil
+}
+
+// createZmodemFile opens a new file in the downloads folder for an incoming
+// one, never overwriting an existing file
+func (a *App) createZmodemFile(f *zmodem.File) (io.WriteCloser, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(home, "Downloads")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	// The remote side picks the name; keep only its last element
+	name := f.Name[strings.LastIndexAny(f.Name, `/\`)+1:]
+	if name == "" || name == "." || name == ".." {
+		name = "download"
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	for i := 0; ; i++ {
+		path := filepath.Join(dir, name)
+		if i > 0 {
+			path = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+		}
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		f.Path = path
+		return file, nil
+	}
+}
+
+// chooseZmodemFiles asks which files to give a waiting rz
+func (a *App) chooseZmodemFiles() ([]string, error) {
+	if a.ctx == nil {
+		return nil, fmt.Errorf("no window to choose files from")
+	}
+	return runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select Files to Send",
+	})
+}
+
+// zmodemTransfer reports the files of one ZMODEM session through the
+// session's transfer queue
+type zmodemTransfer struct {
+	queue *transfer.TransferQueue
+	dir   zmodem.Direction
+	sess  *zmodem.Session
+
+	mu       sync.Mutex
+	item     *transfer.TransferItem
+	stop     chan struct{} // ends the cancel watcher of the current item
+	reported time.Time
+	files    int
+}
+
+func (t *zmodemTransfer) start(f *zmodem.File) {
+	var item *transfer.TransferItem
+	if t.dir == zmodem.Receive {
+		name := f.Name
+		if f.Path != "" {
+			name = filepath.Base(f.Path)
+		}
+		item = t.queue.AddExternal(transfer.Download, name, f.Name, f.Path, f.Size)
+	} else {
+		item = t.queue.AddExternal(transfer.Upload, f.Name, f.Name, f.Path, f.Size)
+	}
+	stop := make(chan struct{})
+
+	t.mu.Lock()
+	t.item, t.stop, t.reported = item, stop, time.Time{}
+	t.mu.Unlock()
+
+	// Cancelling the item in the queue cancels the whole transfer
+	go func() {
+		select {
+		case <-item.Cancelled():
+			t.sess.Cancel()
+		case <-stop:
+		}
+	}()
+}
+
+func (t *zmodemTransfer) progress(f *zmodem.File, bytes int64) {
+	t.mu.Lock()
+	item := t.item
+	due := time.Since(t.reported) >= zmodemProgressInterval || bytes == f.Size
+	if due {
+		t.reported = time.Now()
+	}
+	t.mu.Unlock()
+
+	if item != nil && due {
+		t.queue.SetProgress(item, bytes)
+	}
+}
+
+func (t *zmodemTransfer) done(f *zmodem.File, err error) {
+	t.mu.Lock()
+	item, stop := t.item, t.stop
+	t.item, t.stop = nil, nil
+	if err == nil {
+		t.files++
+	}
+	t.mu.Unlock()
+
+	if item == nil {
+		return
+	}
+	close(stop)
+	if errors.Is(err, zmodem.ErrSkip) {
+		err = fmt.Errorf("skipped by the remote side")
+	}
+	t.queue.Finish(item, err)
+}
+
+func (t *zmodemTransfer) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.files
+}